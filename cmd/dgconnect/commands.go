@@ -4,11 +4,13 @@ import (
 	"context"
 	"encoding/base64"
 	"fmt"
+	"io"
 	"net"
 	"os"
 	"os/signal"
 	"strings"
 	"syscall"
+	"unicode"
 
 	"github.com/opd-ai/go-gamelaunch-client/pkg/dgclient"
 	"github.com/opd-ai/go-gamelaunch-client/pkg/tui"
@@ -22,21 +24,48 @@ import (
 func runConnect(cmd *cobra.Command, args []string) error {
 	var host, user string
 	var actualPort int
+	var loginScript []dgclient.ScriptStep
+	var keySequences map[string][]byte
+	var serverConfig *ServerConfig
+
+	if len(args) > 0 && profileName != "" {
+		return fmt.Errorf("cannot combine a host argument with --profile")
+	}
 
 	// Parse connection string or use config
 	if len(args) > 0 {
-		if err := parseConnectionString(args[0], &user, &host); err != nil {
+		target, err := parseConnectionString(args[0])
+		if err != nil {
 			return err
 		}
-		actualPort = port // Use command line port
+		host = target.Host
+		user = target.User
+		actualPort = target.Port
+		if portSet || actualPort == 0 {
+			// An explicit flag or environment setting overrides the target
+			actualPort = port
+		}
 	} else {
-		// Try to use default server from config
-		defaultServer := viper.GetString("default_server")
-		if defaultServer == "" {
+		// Use the selected profile, or the default server from config
+		serverName := viper.GetString("default_server")
+		if profileName != "" {
+			profile, err := GetProfileConfig(profileName)
+			if err != nil {
+				return err
+			}
+			serverName = profile.Server
+			applyProfile(profile)
+			keySequences, err = decodeKeySequences(profile.KeySequences)
+			if err != nil {
+				return fmt.Errorf("profile '%s': %w", profileName, err)
+			}
+		}
+		if serverName == "" {
 			return fmt.Errorf("no server specified and no default_server in config")
 		}
 
-		serverConfig, err := GetServerConfig(defaultServer)
+		var err error
+		serverConfig, err = GetServerConfig(serverName)
 		if err != nil {
 			return err
 		}
@@ -44,9 +73,11 @@ func runConnect(cmd *cobra.Command, args []string) error {
 		host = serverConfig.Host
 		user = serverConfig.Username
 		actualPort = serverConfig.Port
-		if actualPort == 0 {
-			actualPort = 22
+		if portSet || actualPort == 0 {
+			// An explicit flag or environment setting overrides the server entry
+			actualPort = port
 		}
+		loginScript = serverConfig.LoginScript
 	}
 
 	// Validate required parameters
@@ -60,12 +91,43 @@ func runConnect(cmd *cobra.Command, args []string) error {
 	// Create client configuration
 	clientConfig := dgclient.DefaultClientConfig()
 	clientConfig.Debug = debug
+	clientConfig.DialTimeout = dialTimeout
+	clientConfig.HandshakeTimeout = handshakeTimeout
+	clientConfig.DisableFlowControl = noFlowControl
+	clientConfig.RawLogPath = rawLogPath
+	clientConfig.RawLogInput = rawLogInput
+	clientConfig.KeySequences = keySequences
+	env, err := parseEnvVars(envVars)
+	if err != nil {
+		return err
+	}
+	clientConfig.Env = env
+	if idleRefresh > 0 {
+		keys, err := dgclient.DecodeScriptKeys(idleRefreshKeys)
+		if err != nil {
+			return fmt.Errorf("invalid --idle-refresh-keys: %w", err)
+		}
+		clientConfig.IdleRefreshInterval = idleRefresh
+		clientConfig.IdleRefreshKeys = keys
+	}
+	if repeatWindow > 0 {
+		clientConfig.RepeatWindow = repeatWindow
+		for _, key := range viper.GetStringSlice("repeat_keys") {
+			decoded, err := dgclient.DecodeScriptKeys(key)
+			if err != nil {
+				return fmt.Errorf("invalid repeat_keys entry: %w", err)
+			}
+			clientConfig.RepeatKeys = append(clientConfig.RepeatKeys, string(decoded))
+		}
+		if len(clientConfig.RepeatKeys) == 0 {
+			return fmt.Errorf("--repeat-window needs repeat_keys in the config file, such as the movement keys")
+		}
+	}
 
 	// Set up SSH client config
 	sshConfig := &ssh.ClientConfig{
 		User:            user,
 		HostKeyCallback: getHostKeyCallback(),
-		Timeout:         clientConfig.ConnectTimeout,
 	}
 	clientConfig.SSHConfig = sshConfig
 
@@ -75,9 +137,20 @@ func runConnect(cmd *cobra.Command, args []string) error {
 
 	// Set up view
 	viewOpts := dgclient.DefaultViewOptions()
-	view, err := tui.NewTerminalView(viewOpts)
+	if noTitle {
+		viewOpts.Config[tui.ConfigWindowTitle] = false
+	}
+	viewOpts.Config[tui.ConfigStatusLine] = status
+	if pairs := viper.GetStringMapString("glyph_map"); len(pairs) > 0 {
+		glyphs, err := tui.ParseGlyphMap(pairs)
+		if err != nil {
+			return err
+		}
+		viewOpts.Config[tui.ConfigGlyphMap] = glyphs
+	}
+	view, err := dgclient.CreateView(viewName, viewOpts)
 	if err != nil {
-		return fmt.Errorf("failed to create terminal view: %w", err)
+		return err
 	}
 
 	if err := client.SetView(view); err != nil {
@@ -85,7 +158,7 @@ func runConnect(cmd *cobra.Command, args []string) error {
 	}
 
 	// Get authentication method
-	auth, err := getAuthMethod(user, host)
+	auth, err := getAuthMethod(user, host, serverConfig)
 	if err != nil {
 		return fmt.Errorf("failed to get authentication method: %w", err)
 	}
@@ -97,11 +170,37 @@ func runConnect(cmd *cobra.Command, args []string) error {
 	}
 
 	fmt.Println("Connected successfully!")
+	if banner := client.AuthBanner(); banner != "" {
+		fmt.Println(sanitizeBanner(strings.TrimRight(banner, "\r\n")))
+	}
 
 	// Set up signal handling
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
 
+	// Client commands via the command prefix (Ctrl+] by default)
+	if tv, ok := view.(*tui.TerminalView); ok {
+		tv.SetCommandHandler('q', cancel)
+		tv.SetCommandHandler('r', client.RequestReconnect)
+		tv.SetCommandHandler('i', tv.ToggleStatusLine)
+	}
+
+	// The terminal view reads Ctrl+C as a key, so confirm quitting there.
+	// A game counts as running once the session has drawn output.
+	if tv, ok := view.(*tui.TerminalView); ok && !noQuitConfirm {
+		guard := newQuitGuard(func() bool {
+			return client.IsConnected() && client.OutputStats().Renders > 0
+		})
+		tv.SetInterruptHandler(func() bool {
+			if guard.interrupt() {
+				cancel()
+			} else {
+				tv.SetStatus(fmt.Sprintf("Press Ctrl+C again within %s to quit, or use the game's save command", quitConfirmWindow))
+			}
+			return true
+		})
+	}
+
 	sigCh := make(chan os.Signal, 1)
 	signal.Notify(sigCh, os.Interrupt, syscall.SIGTERM)
 	go func() {
@@ -110,39 +209,133 @@ func runConnect(cmd *cobra.Command, args []string) error {
 		cancel()
 	}()
 
-	// Launch game if specified
-	if gameName != "" {
-		if err := client.SelectGame(gameName); err != nil {
-			fmt.Printf("Warning: failed to select game %s: %v\n", gameName, err)
-		}
+	// Run the login script, then launch the game if specified, alongside
+	// the session
+	scriptErr := make(chan error, 1)
+	if len(loginScript) > 0 || gameName != "" {
+		go func() {
+			if err := client.RunScript(ctx, loginScript); err != nil {
+				scriptErr <- fmt.Errorf("login script failed: %w", err)
+				return
+			}
+			if gameName != "" {
+				if err := client.SelectGame(ctx, gameName); err != nil {
+					scriptErr <- fmt.Errorf("failed to launch game %s: %w", gameName, err)
+					return
+				}
+			}
+			scriptErr <- nil
+		}()
 	}
 
 	// Run the client
-	if err := client.Run(ctx); err != nil {
-		return fmt.Errorf("client error: %w", err)
+	runErr := client.Run(ctx)
+
+	select {
+	case err := <-scriptErr:
+		if err != nil {
+			fmt.Printf("Warning: %v\n", err)
+		}
+	default:
+	}
+
+	if runErr != nil {
+		return fmt.Errorf("client error: %w", runErr)
 	}
 
 	return nil
 }
 
-func parseConnectionString(conn string, user, host *string) error {
-	parts := strings.Split(conn, "@")
-	if len(parts) == 2 {
-		*user = parts[0]
-		*host = parts[1]
-	} else if len(parts) == 1 {
-		*host = parts[0]
-		*user = os.Getenv("USER")
-		if *user == "" {
-			return fmt.Errorf("no username specified and USER environment variable not set")
+// applyProfile fills in view and game preferences from a profile unless
+// they were set explicitly by flag, environment or top-level config
+func applyProfile(profile *ProfileConfig) {
+	if profile.View != "" && !viper.IsSet("view") {
+		viewName = profile.View
+	}
+	if profile.Game != "" && !viper.IsSet("game") {
+		gameName = profile.Game
+	}
+	if !viper.IsSet("status") {
+		status = profile.StatusLine
+	}
+}
+
+func runListProfiles(cmd *cobra.Command, args []string) error {
+	profiles := ListProfiles()
+	if len(profiles) == 0 {
+		fmt.Println("No profiles configured.")
+		return nil
+	}
+
+	for _, name := range profiles {
+		profile, err := GetProfileConfig(name)
+		if err != nil {
+			fmt.Printf("  %-20s (invalid: %v)\n", name, err)
+			continue
 		}
-	} else {
-		return fmt.Errorf("invalid connection string: %s", conn)
+
+		view := profile.View
+		if view == "" {
+			view = "terminal"
+		}
+		fmt.Printf("  %-20s server=%s view=%s", name, profile.Server, view)
+		if profile.Game != "" {
+			fmt.Printf(" game=%s", profile.Game)
+		}
+		fmt.Println()
 	}
 	return nil
 }
 
-func getAuthMethod(user, host string) (dgclient.AuthMethod, error) {
+// parseEnvVars converts KEY=VALUE settings into a map
+func parseEnvVars(vars []string) (map[string]string, error) {
+	if len(vars) == 0 {
+		return nil, nil
+	}
+
+	env := make(map[string]string, len(vars))
+	for _, kv := range vars {
+		name, value, ok := strings.Cut(kv, "=")
+		if !ok || name == "" {
+			return nil, fmt.Errorf("invalid --env %q: expected KEY=VALUE", kv)
+		}
+		env[name] = value
+	}
+	return env, nil
+}
+
+// sanitizeBanner makes an untrusted server banner safe to print, keeping
+// newlines and tabs but showing other control characters, such as the ESC
+// of an escape sequence, as octal escapes like OpenSSH does
+func sanitizeBanner(banner string) string {
+	var sb strings.Builder
+	for _, r := range strings.ToValidUTF8(banner, "?") {
+		if r != '\n' && r != '\t' && unicode.IsControl(r) {
+			fmt.Fprintf(&sb, "\\%03o", r)
+			continue
+		}
+		sb.WriteRune(r)
+	}
+	return sb.String()
+}
+
+// parseConnectionString parses a target argument, taking the user from
+// $USER when the target names none
+func parseConnectionString(conn string) (dgclient.Target, error) {
+	target, err := dgclient.ParseTarget(conn)
+	if err != nil {
+		return dgclient.Target{}, err
+	}
+	if target.User == "" {
+		target.User = os.Getenv("USER")
+		if target.User == "" {
+			return dgclient.Target{}, fmt.Errorf("no username specified and USER environment variable not set")
+		}
+	}
+	return target, nil
+}
+
+func getAuthMethod(user, host string, serverConfig *ServerConfig) (dgclient.AuthMethod, error) {
 	// Priority: command line flag > config > SSH agent > default keys > password prompt
 
 	if password != "" {
@@ -153,22 +346,30 @@ func getAuthMethod(user, host string) (dgclient.AuthMethod, error) {
 		return dgclient.NewKeyAuth(keyPath, ""), nil
 	}
 
-	// Check config for auth method
-	defaultServer := viper.GetString("default_server")
-	if defaultServer != "" {
-		serverConfig, err := GetServerConfig(defaultServer)
-		if err == nil {
-			switch serverConfig.Auth.Method {
-			case "key":
-				if serverConfig.Auth.KeyPath != "" {
-					return dgclient.NewKeyAuth(expandPath(serverConfig.Auth.KeyPath), serverConfig.Auth.Passphrase), nil
-				}
-			case "password":
-				// Will fall through to password prompt
-			case "agent":
-				if os.Getenv("SSH_AUTH_SOCK") != "" {
-					return dgclient.NewAgentAuth(), nil
-				}
+	if keyEnv != "" || keyStdin {
+		key, err := readKeyBytes()
+		if err != nil {
+			return nil, err
+		}
+		passphrase := ""
+		if serverConfig != nil {
+			passphrase = serverConfig.Auth.Passphrase
+		}
+		return dgclient.NewKeyAuthBytes(key, passphrase), nil
+	}
+
+	// Check config for auth method of the selected server
+	if serverConfig != nil {
+		switch serverConfig.Auth.Method {
+		case "key":
+			if serverConfig.Auth.KeyPath != "" {
+				return dgclient.NewKeyAuth(expandPath(serverConfig.Auth.KeyPath), serverConfig.Auth.Passphrase), nil
+			}
+		case "password":
+			// Will fall through to password prompt
+		case "agent":
+			if os.Getenv("SSH_AUTH_SOCK") != "" {
+				return dgclient.NewAgentAuth(), nil
 			}
 		}
 	}
@@ -203,6 +404,31 @@ func getAuthMethod(user, host string) (dgclient.AuthMethod, error) {
 	return dgclient.NewPasswordAuth(string(passwordBytes)), nil
 }
 
+// readKeyBytes returns the private key named by --key-env or read from
+// stdin with --key-stdin
+func readKeyBytes() ([]byte, error) {
+	if keyEnv != "" && keyStdin {
+		return nil, fmt.Errorf("--key-env and --key-stdin cannot be used together")
+	}
+
+	if keyEnv != "" {
+		key := os.Getenv(keyEnv)
+		if key == "" {
+			return nil, fmt.Errorf("environment variable %s is empty or not set", keyEnv)
+		}
+		return []byte(key), nil
+	}
+
+	key, err := io.ReadAll(os.Stdin)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read key from stdin: %w", err)
+	}
+	if len(key) == 0 {
+		return nil, fmt.Errorf("no key data on stdin")
+	}
+	return key, nil
+}
+
 func getHostKeyCallback() ssh.HostKeyCallback {
 	// Try to use known_hosts file first
 	home, err := os.UserHomeDir()
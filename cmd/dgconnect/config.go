@@ -4,16 +4,65 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"sort"
 
+	"github.com/opd-ai/go-gamelaunch-client/pkg/dgclient"
 	"github.com/spf13/viper"
 	"gopkg.in/yaml.v3"
 )
 
 // Config represents the configuration file structure
 type Config struct {
-	DefaultServer string                  `yaml:"default_server,omitempty"`
-	Servers       map[string]ServerConfig `yaml:"servers"`
-	Preferences   PreferencesConfig       `yaml:"preferences,omitempty"`
+	DefaultServer string                   `yaml:"default_server,omitempty"`
+	Servers       map[string]ServerConfig  `yaml:"servers"`
+	Profiles      map[string]ProfileConfig `yaml:"profiles,omitempty"`
+	Preferences   PreferencesConfig        `yaml:"preferences,omitempty"`
+}
+
+// ProfileConfig bundles a server entry with view and game preferences,
+// selected with --profile. Flags given explicitly override profile values.
+type ProfileConfig struct {
+	Server     string `yaml:"server"`
+	View       string `yaml:"view,omitempty"`
+	Game       string `yaml:"game,omitempty"`
+	StatusLine bool   `yaml:"status_line,omitempty" mapstructure:"status_line"`
+
+	// KeySequences remaps keys for this profile's game, each key expanding
+	// into the keystrokes in Send
+	KeySequences []KeySequenceConfig `yaml:"key_sequences,omitempty" mapstructure:"key_sequences"`
+}
+
+// KeySequenceConfig maps one key to a sequence of keystrokes. Both use the
+// escapes accepted by login_script send strings. A list is used rather than
+// a map because configuration map keys are case-insensitive.
+type KeySequenceConfig struct {
+	Key  string `yaml:"key"`
+	Send string `yaml:"send"`
+}
+
+// decodeKeySequences converts profile key sequences into the form used by
+// dgclient.ClientConfig.KeySequences
+func decodeKeySequences(seqs []KeySequenceConfig) (map[string][]byte, error) {
+	if len(seqs) == 0 {
+		return nil, nil
+	}
+
+	decoded := make(map[string][]byte, len(seqs))
+	for _, seq := range seqs {
+		key, err := dgclient.DecodeScriptKeys(seq.Key)
+		if err != nil {
+			return nil, fmt.Errorf("invalid key_sequences key: %w", err)
+		}
+		if len(key) == 0 {
+			return nil, fmt.Errorf("key_sequences entry has an empty key")
+		}
+		send, err := dgclient.DecodeScriptKeys(seq.Send)
+		if err != nil {
+			return nil, fmt.Errorf("invalid key_sequences send for %q: %w", seq.Key, err)
+		}
+		decoded[string(key)] = send
+	}
+	return decoded, nil
 }
 
 // ServerConfig represents a server configuration
@@ -23,6 +72,9 @@ type ServerConfig struct {
 	Username    string     `yaml:"username"`
 	Auth        AuthConfig `yaml:"auth"`
 	DefaultGame string     `yaml:"default_game,omitempty"`
+
+	// LoginScript is run after connecting to automate login and menu navigation
+	LoginScript []dgclient.ScriptStep `yaml:"login_script,omitempty" mapstructure:"login_script"`
 }
 
 // AuthConfig represents authentication configuration
@@ -154,6 +206,15 @@ func ValidateConfig(config *Config) error {
 		}
 	}
 
+	for name, profile := range config.Profiles {
+		if profile.Server == "" {
+			return fmt.Errorf("profile '%s' has no server configured", name)
+		}
+		if _, exists := config.Servers[profile.Server]; !exists {
+			return fmt.Errorf("profile '%s' references unknown server '%s'", name, profile.Server)
+		}
+	}
+
 	return nil
 }
 
@@ -176,3 +237,40 @@ func GetServerConfig(name string) (*ServerConfig, error) {
 
 	return &server, nil
 }
+
+// GetProfileConfig retrieves a profile by name. A server entry name is also
+// accepted and treated as a profile with no extra preferences.
+func GetProfileConfig(name string) (*ProfileConfig, error) {
+	profileKey := fmt.Sprintf("profiles.%s", name)
+	if !viper.IsSet(profileKey) {
+		if viper.IsSet(fmt.Sprintf("servers.%s", name)) {
+			return &ProfileConfig{Server: name}, nil
+		}
+		return nil, fmt.Errorf("profile '%s' not found in configuration", name)
+	}
+
+	var profile ProfileConfig
+	if err := viper.UnmarshalKey(profileKey, &profile); err != nil {
+		return nil, fmt.Errorf("failed to parse profile configuration: %w", err)
+	}
+
+	if profile.Server == "" {
+		return nil, fmt.Errorf("profile '%s' has no server configured", name)
+	}
+	if !viper.IsSet(fmt.Sprintf("servers.%s", profile.Server)) {
+		return nil, fmt.Errorf("profile '%s' references unknown server '%s'", name, profile.Server)
+	}
+
+	return &profile, nil
+}
+
+// ListProfiles returns the sorted names of configured profiles
+func ListProfiles() []string {
+	profiles := viper.GetStringMap("profiles")
+	names := make([]string, 0, len(profiles))
+	for name := range profiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
@@ -4,6 +4,8 @@ import (
 	"os"
 	"path/filepath"
 	"testing"
+
+	"github.com/spf13/viper"
 )
 
 func TestLoadConfig(t *testing.T) {
@@ -184,3 +186,99 @@ func TestGenerateExampleConfig(t *testing.T) {
 		t.Errorf("Generated example config is invalid: %v", err)
 	}
 }
+
+func TestValidateConfigProfileUnknownServer(t *testing.T) {
+	config := &Config{
+		Servers: map[string]ServerConfig{
+			"test-server": {Host: "example.com", Username: "testuser"},
+		},
+		Profiles: map[string]ProfileConfig{
+			"nethack": {Server: "missing-server"},
+		},
+	}
+
+	if err := ValidateConfig(config); err == nil {
+		t.Error("Expected error for profile referencing unknown server")
+	}
+}
+
+func TestGetProfileConfig(t *testing.T) {
+	configPath := filepath.Join(t.TempDir(), "config.yaml")
+	configContent := `
+servers:
+  hardfought:
+    host: hardfought.org
+    username: player
+profiles:
+  nethack:
+    server: hardfought
+    game: nethack
+    status_line: true
+    key_sequences:
+      - key: 'R'
+        send: 'Gj'
+      - key: '\e[A'
+        send: 'k'
+  broken:
+    server: missing
+`
+	if err := os.WriteFile(configPath, []byte(configContent), 0o644); err != nil {
+		t.Fatalf("Failed to create test config file: %v", err)
+	}
+
+	viper.Reset()
+	t.Cleanup(viper.Reset)
+	viper.SetConfigFile(configPath)
+	if err := viper.ReadInConfig(); err != nil {
+		t.Fatalf("Failed to read config: %v", err)
+	}
+
+	profile, err := GetProfileConfig("nethack")
+	if err != nil {
+		t.Fatalf("GetProfileConfig() failed: %v", err)
+	}
+	if profile.Server != "hardfought" || profile.Game != "nethack" || !profile.StatusLine {
+		t.Errorf("Unexpected profile: %+v", profile)
+	}
+	seqs, err := decodeKeySequences(profile.KeySequences)
+	if err != nil {
+		t.Fatalf("decodeKeySequences() failed: %v", err)
+	}
+	if len(seqs) != 2 || string(seqs["R"]) != "Gj" || string(seqs["\x1b[A"]) != "k" {
+		t.Errorf("Expected case-preserving key sequences, got %q", seqs)
+	}
+
+	// Server entries double as bare profiles
+	profile, err = GetProfileConfig("hardfought")
+	if err != nil {
+		t.Fatalf("GetProfileConfig() failed for server name: %v", err)
+	}
+	if profile.Server != "hardfought" {
+		t.Errorf("Expected server hardfought, got %s", profile.Server)
+	}
+
+	if _, err := GetProfileConfig("broken"); err == nil {
+		t.Error("Expected error for profile referencing unknown server")
+	}
+	if _, err := GetProfileConfig("unknown"); err == nil {
+		t.Error("Expected error for unknown profile")
+	}
+
+	names := ListProfiles()
+	if len(names) != 2 || names[0] != "broken" || names[1] != "nethack" {
+		t.Errorf("Expected [broken nethack], got %v", names)
+	}
+}
+
+func TestDecodeKeySequencesErrors(t *testing.T) {
+	tests := []KeySequenceConfig{
+		{Key: "", Send: "x"},
+		{Key: `\q`, Send: "x"},
+		{Key: "x", Send: `\x1`},
+	}
+	for _, seq := range tests {
+		if _, err := decodeKeySequences([]KeySequenceConfig{seq}); err == nil {
+			t.Errorf("Expected error for %+v", seq)
+		}
+	}
+}
@@ -0,0 +1,34 @@
+package main
+
+import "time"
+
+// quitConfirmWindow is how long a second interrupt has to arrive to force quit
+const quitConfirmWindow = 3 * time.Second
+
+// quitGuard asks for a second interrupt before quitting while a game appears
+// to be running, so a stray Ctrl+C does not kill an unsaved game
+type quitGuard struct {
+	window     time.Duration
+	gameActive func() bool
+	now        func() time.Time
+
+	last time.Time
+}
+
+func newQuitGuard(gameActive func() bool) *quitGuard {
+	return &quitGuard{window: quitConfirmWindow, gameActive: gameActive, now: time.Now}
+}
+
+// interrupt records an interrupt and reports whether to quit now
+func (g *quitGuard) interrupt() bool {
+	if !g.gameActive() {
+		return true
+	}
+
+	now := g.now()
+	if !g.last.IsZero() && now.Sub(g.last) <= g.window {
+		return true
+	}
+	g.last = now
+	return false
+}
@@ -0,0 +1,35 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestQuitGuard(t *testing.T) {
+	active := true
+	now := time.Unix(0, 0)
+	g := newQuitGuard(func() bool { return active })
+	g.now = func() time.Time { return now }
+
+	if g.interrupt() {
+		t.Fatal("Expected first interrupt during a game to ask for confirmation")
+	}
+	now = now.Add(2 * time.Second)
+	if !g.interrupt() {
+		t.Error("Expected second interrupt within the window to quit")
+	}
+
+	// A second interrupt after the window starts a new confirmation
+	g = newQuitGuard(func() bool { return active })
+	g.now = func() time.Time { return now }
+	g.interrupt()
+	now = now.Add(4 * time.Second)
+	if g.interrupt() {
+		t.Error("Expected interrupt after the window to ask again")
+	}
+
+	active = false
+	if !g.interrupt() {
+		t.Error("Expected interrupt with no game running to quit immediately")
+	}
+}
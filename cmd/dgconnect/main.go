@@ -3,6 +3,7 @@ package main
 import (
 	"fmt"
 	"os"
+	"time"
 
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
@@ -15,14 +16,31 @@ var (
 	date    = "unknown"
 
 	// Configuration
-	cfgFile string
+	cfgFile     string
+	profileName string
 
 	// Command flags
-	port     int
-	keyPath  string
-	password string
-	gameName string
-	debug    bool
+	port             int
+	portSet          bool
+	keyPath          string
+	keyEnv           string
+	keyStdin         bool
+	password         string
+	gameName         string
+	viewName         string
+	noTitle          bool
+	status           bool
+	dialTimeout      time.Duration
+	handshakeTimeout time.Duration
+	repeatWindow     time.Duration
+	idleRefresh      time.Duration
+	idleRefreshKeys  string
+	rawLogPath       string
+	rawLogInput      bool
+	noFlowControl    bool
+	envVars          []string
+	noQuitConfirm    bool
+	debug            bool
 )
 
 func main() {
@@ -33,7 +51,7 @@ func main() {
 }
 
 var rootCmd = &cobra.Command{
-	Use:   "dgconnect [user@]host",
+	Use:   "dgconnect [user@]host[:port]",
 	Short: "Connect to dgamelaunch SSH servers",
 	Long: `dgconnect is a client for connecting to dgamelaunch-style SSH servers
 to play terminal-based roguelike games remotely.
@@ -41,8 +59,11 @@ to play terminal-based roguelike games remotely.
 Examples:
   dgconnect user@nethack.example.com
   dgconnect user@server.example.com --port 2022 --key ~/.ssh/id_rsa
+  dgconnect ssh://user@server.example.com:2022
   dgconnect --config ~/.dgconnect.yaml nethack-server
-  dgconnect user@server.example.com --game nethack`,
+  dgconnect user@server.example.com --game nethack
+  dgconnect user@server.example.com --view terminal
+  dgconnect --profile hardfought`,
 	Args: cobra.MaximumNArgs(1),
 	RunE: runConnect,
 }
@@ -53,12 +74,31 @@ func init() {
 	// Global flags
 	rootCmd.PersistentFlags().StringVar(&cfgFile, "config", "", "config file (default is $HOME/.dgconnect.yaml)")
 	rootCmd.PersistentFlags().BoolVar(&debug, "debug", false, "enable debug output")
+	rootCmd.PersistentFlags().StringVar(&profileName, "profile", "", "connect using a named profile from the config file")
 
 	// Connection flags
 	rootCmd.Flags().IntVarP(&port, "port", "p", 22, "SSH port")
 	rootCmd.Flags().StringVarP(&keyPath, "key", "k", "", "SSH private key path")
+	rootCmd.Flags().StringVar(&keyEnv, "key-env", "", "read the SSH private key from this environment variable")
+	rootCmd.Flags().BoolVar(&keyStdin, "key-stdin", false, "read the SSH private key from stdin")
 	rootCmd.Flags().StringVar(&password, "password", "", "SSH password (use with caution)")
+	rootCmd.Flags().DurationVar(&dialTimeout, "dial-timeout", 0, "TCP connect timeout (0 uses the overall connection timeout)")
+	rootCmd.Flags().DurationVar(&handshakeTimeout, "handshake-timeout", 0, "SSH handshake and authentication timeout (0 uses the overall connection timeout)")
 	rootCmd.Flags().StringVarP(&gameName, "game", "g", "", "game to launch directly")
+	rootCmd.Flags().StringVar(&viewName, "view", "terminal", "view to render the game with")
+	rootCmd.Flags().BoolVar(&noTitle, "no-title", false, "do not set the terminal window title from the game")
+	rootCmd.Flags().BoolVar(&status, "status", false, "show a connection status line at the bottom of the screen")
+	rootCmd.Flags().DurationVar(&repeatWindow, "repeat-window", 0, "drop repeats of the same key within this window (requires repeat_keys in config)")
+	rootCmd.Flags().DurationVar(&idleRefresh, "idle-refresh", 0, "send --idle-refresh-keys after this long without server output")
+	rootCmd.Flags().StringVar(&idleRefreshKeys, "idle-refresh-keys", `\x12`, "keys sent by --idle-refresh (escapes as in login_script; \\x12 is Ctrl+R)")
+	rootCmd.Flags().StringArrayVar(&envVars, "env", nil, "request a remote environment variable as KEY=VALUE (repeatable; the server may ignore it)")
+	rootCmd.Flags().BoolVar(&noQuitConfirm, "no-quit-confirm", false, "send Ctrl+C to the game instead of asking to confirm quitting")
+	rootCmd.Flags().BoolVar(&noFlowControl, "no-flow-control", false, "disable XON/XOFF so Ctrl+S and Ctrl+Q are sent to the game")
+	rootCmd.Flags().StringVar(&rawLogPath, "raw-log", "", "append timestamped raw server output to this file for debugging")
+	rootCmd.Flags().BoolVar(&rawLogInput, "raw-log-input", false, "also record sent input in the --raw-log file")
+
+	// Flags > DGCONNECT_* environment > config file > defaults
+	cobra.CheckErr(configureViper(viper.GetViper(), rootCmd.PersistentFlags(), rootCmd.Flags()))
 
 	// Version command
 	rootCmd.AddCommand(&cobra.Command{
@@ -69,6 +109,14 @@ func init() {
 		},
 	})
 
+	// Profiles command
+	rootCmd.AddCommand(&cobra.Command{
+		Use:   "profiles",
+		Short: "List configured connection profiles",
+		Args:  cobra.NoArgs,
+		RunE:  runListProfiles,
+	})
+
 	// Init command
 	rootCmd.AddCommand(&cobra.Command{
 		Use:   "init [config-file]",
@@ -98,13 +146,13 @@ func initConfig() {
 		viper.SetConfigName(".dgconnect")
 	}
 
-	viper.AutomaticEnv()
-
 	if err := viper.ReadInConfig(); err == nil {
-		if debug {
+		if viper.GetBool("debug") {
 			fmt.Println("Using config file:", viper.ConfigFileUsed())
 		}
 	}
+
+	applySettings(viper.GetViper())
 }
 
 func runInitConfig(cmd *cobra.Command, args []string) error {
@@ -0,0 +1,73 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/spf13/pflag"
+	"github.com/spf13/viper"
+)
+
+// envPrefix namespaces environment variables, e.g. DGCONNECT_PORT
+const envPrefix = "DGCONNECT"
+
+// configureViper sets up v so that every setting resolves with the precedence
+//
+//	command-line flag > environment variable (DGCONNECT_*) > config file > flag default
+//
+// Each flag is bound to a Viper key of the same name with dashes replaced by
+// underscores, so --no-title maps to the no_title key and DGCONNECT_NO_TITLE.
+func configureViper(v *viper.Viper, flags ...*pflag.FlagSet) error {
+	v.SetEnvPrefix(envPrefix)
+	v.SetEnvKeyReplacer(strings.NewReplacer("-", "_", ".", "_"))
+	v.AutomaticEnv()
+
+	for _, fs := range flags {
+		var bindErr error
+		fs.VisitAll(func(f *pflag.Flag) {
+			if bindErr != nil || f.Name == "config" {
+				return
+			}
+			if err := v.BindPFlag(settingKey(f.Name), f); err != nil {
+				bindErr = fmt.Errorf("failed to bind flag --%s: %w", f.Name, err)
+			}
+		})
+		if bindErr != nil {
+			return bindErr
+		}
+	}
+
+	return nil
+}
+
+// settingKey returns the Viper key for a flag name
+func settingKey(flagName string) string {
+	return strings.ReplaceAll(flagName, "-", "_")
+}
+
+// applySettings copies the effective values into the flag variables so the
+// rest of the command sees flag, environment and config file settings alike
+func applySettings(v *viper.Viper) {
+	debug = v.GetBool("debug")
+	profileName = v.GetString("profile")
+	port = v.GetInt("port")
+	portSet = v.IsSet("port")
+	keyPath = v.GetString("key")
+	keyEnv = v.GetString(settingKey("key-env"))
+	keyStdin = v.GetBool(settingKey("key-stdin"))
+	password = v.GetString("password")
+	dialTimeout = v.GetDuration(settingKey("dial-timeout"))
+	handshakeTimeout = v.GetDuration(settingKey("handshake-timeout"))
+	gameName = v.GetString("game")
+	viewName = v.GetString("view")
+	noTitle = v.GetBool(settingKey("no-title"))
+	status = v.GetBool("status")
+	repeatWindow = v.GetDuration(settingKey("repeat-window"))
+	idleRefresh = v.GetDuration(settingKey("idle-refresh"))
+	idleRefreshKeys = v.GetString(settingKey("idle-refresh-keys"))
+	noFlowControl = v.GetBool(settingKey("no-flow-control"))
+	envVars = v.GetStringSlice("env")
+	noQuitConfirm = v.GetBool(settingKey("no-quit-confirm"))
+	rawLogPath = v.GetString(settingKey("raw-log"))
+	rawLogInput = v.GetBool(settingKey("raw-log-input"))
+}
@@ -0,0 +1,131 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/spf13/pflag"
+	"github.com/spf13/viper"
+)
+
+// newTestSettings builds a Viper instance with a config file, bound flags
+// parsed from args, and the precedence rules from configureViper
+func newTestSettings(t *testing.T, configContent string, args ...string) *viper.Viper {
+	t.Helper()
+
+	configPath := filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(configPath, []byte(configContent), 0o644); err != nil {
+		t.Fatalf("Failed to write config: %v", err)
+	}
+
+	flags := pflag.NewFlagSet("test", pflag.ContinueOnError)
+	flags.Int("port", 22, "SSH port")
+	flags.String("view", "terminal", "view")
+	flags.Bool("no-title", false, "no title")
+	flags.StringArray("env", nil, "env")
+
+	v := viper.New()
+	if err := configureViper(v, flags); err != nil {
+		t.Fatalf("configureViper() failed: %v", err)
+	}
+	if err := flags.Parse(args); err != nil {
+		t.Fatalf("Failed to parse flags: %v", err)
+	}
+
+	v.SetConfigFile(configPath)
+	if err := v.ReadInConfig(); err != nil {
+		t.Fatalf("Failed to read config: %v", err)
+	}
+
+	return v
+}
+
+func TestSettingsPrecedence(t *testing.T) {
+	config := "port: 1000\nview: file-view\n"
+
+	tests := []struct {
+		name     string
+		env      string
+		args     []string
+		wantPort int
+	}{
+		{"default", "", nil, 22},
+		{"config file", "", nil, 1000},
+		{"env overrides file", "2000", nil, 2000},
+		{"flag overrides env", "2000", []string{"--port", "3000"}, 3000},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.env != "" {
+				t.Setenv("DGCONNECT_PORT", tt.env)
+			}
+
+			content := config
+			if tt.name == "default" {
+				content = "view: file-view\n"
+			}
+
+			v := newTestSettings(t, content, tt.args...)
+			if got := v.GetInt("port"); got != tt.wantPort {
+				t.Errorf("Expected port %d, got %d", tt.wantPort, got)
+			}
+			if tt.name == "default" && v.IsSet("port") {
+				t.Error("Expected default port not to count as explicitly set")
+			}
+		})
+	}
+}
+
+func TestSettingsDashedFlagKeys(t *testing.T) {
+	t.Setenv("DGCONNECT_NO_TITLE", "true")
+
+	v := newTestSettings(t, "view: file-view\n")
+	if !v.GetBool(settingKey("no-title")) {
+		t.Error("Expected DGCONNECT_NO_TITLE to set no_title")
+	}
+	if got := v.GetString("view"); got != "file-view" {
+		t.Errorf("Expected view from config file, got %q", got)
+	}
+}
+
+func TestSettingsEnvVars(t *testing.T) {
+	v := newTestSettings(t, "env: ['LANG=C']\n", "--env", "NETHACKOPTIONS=color,time", "--env", "LANG=en_US.UTF-8")
+	env, err := parseEnvVars(v.GetStringSlice("env"))
+	if err != nil {
+		t.Fatalf("parseEnvVars() failed: %v", err)
+	}
+	if len(env) != 2 || env["NETHACKOPTIONS"] != "color,time" || env["LANG"] != "en_US.UTF-8" {
+		t.Errorf("Expected flag values to replace the config list, got %v", env)
+	}
+
+	v = newTestSettings(t, "env: ['LANG=C']\n")
+	if env, _ := parseEnvVars(v.GetStringSlice("env")); env["LANG"] != "C" {
+		t.Errorf("Expected env from config file, got %v", env)
+	}
+
+	if _, err := parseEnvVars([]string{"NOVALUE"}); err == nil {
+		t.Error("Expected error for entry without '='")
+	}
+	if _, err := parseEnvVars([]string{"=x"}); err == nil {
+		t.Error("Expected error for empty name")
+	}
+}
+
+func TestSanitizeBanner(t *testing.T) {
+	tests := []struct {
+		banner string
+		want   string
+	}{
+		{"Welcome!\n\tEnjoy", "Welcome!\n\tEnjoy"},
+		{"\x1b]0;pwned\x07\x1b[2Jhi\r", `\033]0;pwned\007\033[2Jhi\015`},
+		{"bad\xffbyte \u009b1m", `bad?byte \2331m`},
+	}
+
+	for _, tt := range tests {
+		if got := sanitizeBanner(tt.banner); got != tt.want {
+			t.Errorf("sanitizeBanner(%q) = %q, expected %q", tt.banner, got, tt.want)
+		}
+	}
+}
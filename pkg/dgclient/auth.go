@@ -41,6 +41,7 @@ func (p *PasswordAuth) Name() string {
 // KeyAuth implements key-based authentication
 type KeyAuth struct {
 	keyPath    string
+	key        []byte
 	passphrase string
 }
 
@@ -52,13 +53,28 @@ func NewKeyAuth(keyPath, passphrase string) AuthMethod {
 	}
 }
 
+// NewKeyAuthBytes creates a key authentication method from PEM-encoded
+// private key bytes, e.g. read from an environment variable or a secret
+// mount, so the key never has to be written to disk
+func NewKeyAuthBytes(key []byte, passphrase string) AuthMethod {
+	return &KeyAuth{
+		key:        key,
+		passphrase: passphrase,
+	}
+}
+
 func (k *KeyAuth) GetSSHAuthMethod() (ssh.AuthMethod, error) {
-	key, err := os.ReadFile(k.keyPath)
-	if err != nil {
-		return nil, fmt.Errorf("failed to read key file: %w", err)
+	key := k.key
+	if key == nil {
+		var err error
+		key, err = os.ReadFile(k.keyPath)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read key file: %w", err)
+		}
 	}
 
 	var signer ssh.Signer
+	var err error
 	if k.passphrase != "" {
 		signer, err = ssh.ParsePrivateKeyWithPassphrase(key, []byte(k.passphrase))
 	} else {
@@ -1,9 +1,14 @@
 package dgclient
 
 import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/pem"
 	"os"
 	"path/filepath"
 	"testing"
+
+	"golang.org/x/crypto/ssh"
 )
 
 func TestPasswordAuth(t *testing.T) {
@@ -75,3 +80,47 @@ func TestKeyAuthNonexistentFile(t *testing.T) {
 		t.Error("Expected error with nonexistent key file")
 	}
 }
+
+func TestKeyAuthBytes(t *testing.T) {
+	_, priv, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatalf("Failed to generate key: %v", err)
+	}
+	plain, err := ssh.MarshalPrivateKey(priv, "")
+	if err != nil {
+		t.Fatalf("Failed to marshal key: %v", err)
+	}
+	encrypted, err := ssh.MarshalPrivateKeyWithPassphrase(priv, "", []byte("secret"))
+	if err != nil {
+		t.Fatalf("Failed to marshal encrypted key: %v", err)
+	}
+
+	tests := []struct {
+		name       string
+		key        []byte
+		passphrase string
+		wantErr    bool
+	}{
+		{"plain key", pem.EncodeToMemory(plain), "", false},
+		{"encrypted key", pem.EncodeToMemory(encrypted), "secret", false},
+		{"wrong passphrase", pem.EncodeToMemory(encrypted), "wrong", true},
+		{"invalid key", []byte("not a key"), "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			auth := NewKeyAuthBytes(tt.key, tt.passphrase)
+			if auth.Name() != "key" {
+				t.Errorf("Expected name 'key', got '%s'", auth.Name())
+			}
+
+			sshAuth, err := auth.GetSSHAuthMethod()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Expected error %v, got %v", tt.wantErr, err)
+			}
+			if !tt.wantErr && sshAuth == nil {
+				t.Error("GetSSHAuthMethod() returned nil")
+			}
+		})
+	}
+}
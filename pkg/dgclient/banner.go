@@ -0,0 +1,81 @@
+package dgclient
+
+import "fmt"
+
+// maxBannerSize caps the pre-game output retained for LastBanner
+const maxBannerSize = 64 * 1024
+
+// LastBanner returns the output of the current session received before the
+// first game was launched with SelectGame, such as the server's login banner
+// and menu.
+func (c *Client) LastBanner() string {
+	c.bannerMu.Lock()
+	defer c.bannerMu.Unlock()
+	return string(c.banner)
+}
+
+// trackBanner captures output received before a game is launched and
+// reports whether it should be rendered to the view
+func (c *Client) trackBanner(data []byte) bool {
+	c.bannerMu.Lock()
+	defer c.bannerMu.Unlock()
+
+	if c.gameStarted {
+		return true
+	}
+
+	if room := maxBannerSize - len(c.banner); room > 0 {
+		if len(data) > room {
+			data = data[:room]
+		}
+		c.banner = append(c.banner, data...)
+	}
+	return !c.config.SuppressBannerUntilGame
+}
+
+// markGameStarted ends banner capture; subsequent output is game output
+func (c *Client) markGameStarted() {
+	c.bannerMu.Lock()
+	defer c.bannerMu.Unlock()
+	c.gameStarted = true
+}
+
+// resetBanner restarts banner capture for a new session, so a reconnect
+// captures (and, if configured, withholds) the new session's menu
+func (c *Client) resetBanner() {
+	c.bannerMu.Lock()
+	defer c.bannerMu.Unlock()
+	c.banner = nil
+	c.gameStarted = false
+}
+
+// AuthBanner returns the SSH authentication banner sent by the server during
+// the last connect, or "" if it sent none. Unlike LastBanner, this arrives
+// before login and is not part of the session output.
+func (c *Client) AuthBanner() string {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.authBanner
+}
+
+// bannerCallback records the authentication banner. It runs during the
+// handshake, while connect holds c.mu.
+func (c *Client) bannerCallback(message string) error {
+	c.authBanner = message
+	return nil
+}
+
+// deliverAuthBanner passes the banner from the last connect to the
+// configured BannerCallback once c.mu is released, so the callback may use
+// the client. If it fails, the new connection is closed.
+func (c *Client) deliverAuthBanner() error {
+	banner := c.AuthBanner()
+	if banner == "" || c.config.BannerCallback == nil {
+		return nil
+	}
+	if err := c.config.BannerCallback(banner); err != nil {
+		c.disconnect()
+		return fmt.Errorf("banner callback rejected the connection: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,198 @@
+package dgclient
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"errors"
+	"io"
+	"net"
+	"testing"
+	"time"
+
+	"golang.org/x/crypto/ssh"
+)
+
+func TestLastBanner(t *testing.T) {
+	client := NewClient(nil)
+	defer client.Close()
+
+	if !client.trackBanner([]byte("Welcome to dgamelaunch\r\n")) {
+		t.Error("Expected banner to be rendered without suppression")
+	}
+	client.markGameStarted()
+	client.trackBanner([]byte("NetHack"))
+
+	if got := client.LastBanner(); got != "Welcome to dgamelaunch\r\n" {
+		t.Errorf("Expected banner %q, got %q", "Welcome to dgamelaunch\r\n", got)
+	}
+}
+
+func TestRunSessionSuppressesBanner(t *testing.T) {
+	config := DefaultClientConfig()
+	config.SuppressBannerUntilGame = true
+	view := newScriptedView()
+	close(view.inputCh)
+
+	runFakeSession(t, config, view, "## dgamelaunch menu")
+
+	if got := view.Rendered(); got != "" {
+		t.Errorf("Expected banner to be withheld from the view, got %q", got)
+	}
+}
+
+func TestRunSessionRendersAfterGameStart(t *testing.T) {
+	config := DefaultClientConfig()
+	config.SuppressBannerUntilGame = true
+	view := newScriptedView()
+	close(view.inputCh)
+
+	pr, pw := io.Pipe()
+	client := NewClient(config)
+	defer client.Close()
+	client.view = view
+	client.session = &fakeSession{stdout: pr}
+
+	done := make(chan error, 1)
+	go func() { done <- client.runSession(context.Background()) }()
+
+	pw.Write([]byte("menu"))
+	waitForBanner(t, client, "menu")
+	client.markGameStarted()
+	pw.Write([]byte("game"))
+	pw.Close()
+
+	if err := <-done; err != nil {
+		t.Fatalf("runSession() failed: %v", err)
+	}
+	if got := view.Rendered(); got != "game" {
+		t.Errorf("Expected only game output rendered, got %q", got)
+	}
+}
+
+func TestRunSessionResetsBanner(t *testing.T) {
+	config := DefaultClientConfig()
+	config.SuppressBannerUntilGame = true
+	client := NewClient(config)
+	defer client.Close()
+
+	// State left over from a session that reached a game
+	client.trackBanner([]byte("old menu"))
+	client.markGameStarted()
+
+	// A reconnect starts a new session that shows the menu again
+	view := newScriptedView()
+	close(view.inputCh)
+	runFakeClient(t, client, view, "new menu")
+
+	if got := client.LastBanner(); got != "new menu" {
+		t.Errorf("Expected banner %q, got %q", "new menu", got)
+	}
+	if got := view.Rendered(); got != "" {
+		t.Errorf("Expected new menu to be withheld from the view, got %q", got)
+	}
+}
+
+// waitForBanner waits until the client has captured want as its banner
+func waitForBanner(t *testing.T, client *Client, want string) {
+	t.Helper()
+	deadline := time.Now().Add(time.Second)
+	for client.LastBanner() != want {
+		if time.Now().After(deadline) {
+			t.Fatalf("Expected banner %q, got %q", want, client.LastBanner())
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+// startBannerServer runs an SSH server on a loopback port that sends banner
+// before accepting any password, and returns its port
+func startBannerServer(t *testing.T, banner string) int {
+	t.Helper()
+	_, key, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatalf("Failed to generate host key: %v", err)
+	}
+	signer, err := ssh.NewSignerFromKey(key)
+	if err != nil {
+		t.Fatalf("Failed to create signer: %v", err)
+	}
+	config := &ssh.ServerConfig{
+		PasswordCallback: func(ssh.ConnMetadata, []byte) (*ssh.Permissions, error) { return nil, nil },
+		BannerCallback:   func(ssh.ConnMetadata) string { return banner },
+	}
+	config.AddHostKey(signer)
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Failed to listen: %v", err)
+	}
+	t.Cleanup(func() { ln.Close() })
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				sshConn, chans, reqs, err := ssh.NewServerConn(conn, config)
+				if err != nil {
+					conn.Close()
+					return
+				}
+				defer sshConn.Close()
+				go ssh.DiscardRequests(reqs)
+				for ch := range chans {
+					ch.Reject(ssh.Prohibited, "no sessions")
+				}
+			}()
+		}
+	}()
+	return ln.Addr().(*net.TCPAddr).Port
+}
+
+func TestAuthBanner(t *testing.T) {
+	port := startBannerServer(t, "Tournament starts Friday!\n")
+
+	var got []string
+	var client *Client
+	config := DefaultClientConfig()
+	config.SSHConfig = &ssh.ClientConfig{User: "test", HostKeyCallback: ssh.InsecureIgnoreHostKey()}
+	config.BannerCallback = func(message string) error {
+		// The callback may use the client without deadlocking
+		if client.AuthBanner() != message || !client.IsConnected() {
+			t.Errorf("Expected a connected client with the banner inside the callback")
+		}
+		got = append(got, message)
+		return nil
+	}
+	client = NewClient(config)
+	defer client.Close()
+
+	if err := client.Connect("127.0.0.1", port, NewPasswordAuth("secret")); err != nil {
+		t.Fatalf("Connect failed: %v", err)
+	}
+	if len(got) != 1 || got[0] != "Tournament starts Friday!\n" {
+		t.Errorf("Expected banner callback once, got %q", got)
+	}
+	if banner := client.AuthBanner(); banner != "Tournament starts Friday!\n" {
+		t.Errorf("Expected AuthBanner to return the banner, got %q", banner)
+	}
+}
+
+func TestAuthBannerCallbackError(t *testing.T) {
+	port := startBannerServer(t, "closed for maintenance")
+
+	config := DefaultClientConfig()
+	config.SSHConfig = &ssh.ClientConfig{User: "test", HostKeyCallback: ssh.InsecureIgnoreHostKey()}
+	config.BannerCallback = func(string) error { return errors.New("refused") }
+	client := NewClient(config)
+	defer client.Close()
+
+	if err := client.Connect("127.0.0.1", port, NewPasswordAuth("secret")); err == nil {
+		t.Error("Expected connect to fail when the banner callback errors")
+	}
+	if client.IsConnected() {
+		t.Error("Expected the connection to be closed after the callback failed")
+	}
+}
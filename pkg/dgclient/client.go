@@ -1,11 +1,19 @@
 package dgclient
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"io"
+	"net"
+	"os"
 	"regexp"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
+	"unicode"
+	"unicode/utf8"
 
 	"golang.org/x/crypto/ssh"
 )
@@ -23,19 +31,115 @@ type ClientConfig struct {
 	// SSH client configuration
 	SSHConfig *ssh.ClientConfig
 
-	// Connection settings
+	// Connection settings. DialTimeout bounds the TCP dial and
+	// HandshakeTimeout the SSH key exchange and authentication; either
+	// falls back to ConnectTimeout when zero.
 	ConnectTimeout    time.Duration
+	DialTimeout       time.Duration
+	HandshakeTimeout  time.Duration
 	KeepAliveInterval time.Duration
 
+	// RequestCompression asks for SSH transport compression. The
+	// golang.org/x/crypto/ssh transport implements no compression
+	// algorithms, so none is negotiated today; OutputStats.Compressed
+	// reports the result so callers need not assume either way.
+	RequestCompression bool
+
+	// Dial opens the transport connection for Connect. A nil Dial uses
+	// net.DialTimeout.
+	Dial func(network, address string, timeout time.Duration) (net.Conn, error)
+
+	// BannerCallback receives the SSH authentication banner (the server's
+	// pre-auth message, often an announcement or MOTD) on every connect,
+	// once the handshake has finished. Returning an error closes the new
+	// connection and fails the connect. The last banner is also available
+	// from AuthBanner.
+	BannerCallback func(message string) error
+
 	// Retry settings
 	MaxReconnectAttempts int
 	ReconnectDelay       time.Duration
 
-	// Terminal settings
-	DefaultTerminal string
-
-	// Debug options
-	Debug bool
+	// Terminal settings. TerminalModes are added to (or override)
+	// DefaultTerminalModes in the PTY request. DisableFlowControl turns off
+	// XON/XOFF (IXON, IXOFF) so Ctrl+S and Ctrl+Q reach the game instead of
+	// pausing and resuming output on the server's terminal.
+	DefaultTerminal    string
+	TerminalModes      ssh.TerminalModes
+	DisableFlowControl bool
+
+	// Env lists environment variables (e.g. NETHACKOPTIONS, LANG) requested
+	// for the session before the shell starts. Servers only accept names
+	// allowed by their AcceptEnv setting; rejected variables are skipped, as
+	// are all of them if the Session does not implement EnvSession.
+	Env map[string]string
+
+	// SizeReadyTimeout bounds how long a session waits for a SizeReadyView
+	// to report its size before requesting the PTY with the current size
+	SizeReadyTimeout time.Duration
+
+	// Input batching: when InputFlushInterval is non-zero, bursts of input
+	// arriving within the interval are coalesced into a single write, flushed
+	// early once InputFlushThreshold bytes are pending. Isolated keystrokes
+	// are always written immediately.
+	InputFlushInterval  time.Duration
+	InputFlushThreshold int
+
+	// OutputBufferSize bounds output queued for a slow view. When non-zero,
+	// chunks that pile up while the view renders are merged into a single
+	// Render call; reads block once this many bytes are pending. Zero
+	// renders each read synchronously.
+	OutputBufferSize int
+
+	// Byte filters: InputFilter is applied to user input and data passed to
+	// Send before it is written to the session, OutputFilter to server output
+	// before it is rendered.
+	// A nil filter passes data through unchanged; returning an empty slice
+	// drops the data entirely.
+	InputFilter  func([]byte) []byte
+	OutputFilter func([]byte) []byte
+
+	// Key repeat debouncing: when RepeatWindow is non-zero, a key identical
+	// to the previous one is dropped if it arrives within RepeatWindow of the
+	// last one sent, limiting held keys to one event per window. RepeatKeys
+	// lists the input sequences to debounce (e.g. "h", "\x1b[A"); keys not
+	// listed, and all keys when it is empty, are never debounced.
+	RepeatWindow time.Duration
+	RepeatKeys   []string
+
+	// KeySequences expands a key into several keystrokes: input that exactly
+	// matches a trigger (e.g. "\x1b[A" or "R") is replaced by the mapped
+	// bytes, sent in order as one write. Expansion happens after repeat
+	// debouncing and before InputNormalization and InputFilter.
+	KeySequences map[string][]byte
+
+	// Idle refresh: when IdleRefreshInterval and IdleRefreshKeys are set,
+	// the keys (e.g. Ctrl+R) are sent whenever the interval passes without
+	// server output, prompting games that never repaint on their own to
+	// redraw a stale screen. This is separate from the SSH keepalive.
+	IdleRefreshInterval time.Duration
+	IdleRefreshKeys     []byte
+
+	// WelcomeMessage is rendered to the view as soon as it is set, before
+	// any server output, and cleared by a terminal reset when the first
+	// output arrives. ANSI sequences are allowed.
+	WelcomeMessage []byte
+
+	// SuppressBannerUntilGame withholds output from the view until a game
+	// is launched with SelectGame. The withheld output remains available
+	// through LastBanner.
+	SuppressBannerUntilGame bool
+
+	// InputNormalization applies a Unicode normalization form to typed and
+	// pasted input before InputFilter. The default sends input unchanged.
+	InputNormalization InputNormalization
+
+	// Debug options. RawLogPath, when set, appends every chunk of server
+	// output to the named file with a timestamp; RawLogInput also logs the
+	// input sent from the view.
+	Debug       bool
+	RawLogPath  string
+	RawLogInput bool
 }
 
 // DefaultClientConfig returns a client configuration with sensible defaults
@@ -46,10 +150,40 @@ func DefaultClientConfig() *ClientConfig {
 		MaxReconnectAttempts: 3,
 		ReconnectDelay:       5 * time.Second,
 		DefaultTerminal:      "xterm-256color",
+		SizeReadyTimeout:     5 * time.Second,
 		Debug:                false,
 	}
 }
 
+// dialTimeout returns the timeout for the TCP dial
+func (cfg *ClientConfig) dialTimeout() time.Duration {
+	if cfg.DialTimeout > 0 {
+		return cfg.DialTimeout
+	}
+	return cfg.ConnectTimeout
+}
+
+// handshakeTimeout returns the timeout for the SSH handshake
+func (cfg *ClientConfig) handshakeTimeout() time.Duration {
+	if cfg.HandshakeTimeout > 0 {
+		return cfg.HandshakeTimeout
+	}
+	return cfg.ConnectTimeout
+}
+
+// terminalModes returns the modes to request with the PTY
+func (cfg *ClientConfig) terminalModes() ssh.TerminalModes {
+	modes := DefaultTerminalModes()
+	for op, value := range cfg.TerminalModes {
+		modes[op] = value
+	}
+	if cfg.DisableFlowControl {
+		modes[ssh.IXON] = 0
+		modes[ssh.IXOFF] = 0
+	}
+	return modes
+}
+
 // Client manages connections to dgamelaunch servers
 type Client struct {
 	config *ClientConfig
@@ -60,17 +194,51 @@ type Client struct {
 	session   Session
 	connected bool
 
+	// Writes input through the running session's input path, if any
+	sessionInput func([]byte) error
+
 	// View management
 	view   View
 	viewMu sync.RWMutex
 
 	// Current connection info
-	host string
-	port int
+	host       string
+	port       int
+	auth       AuthMethod
+	authBanner string
+
+	// Game list from the last successful ListGames, cleared on disconnect
+	games []GameInfo
+
+	// Connection event notifications
+	eventHandler EventHandler
+
+	// Keepalive round-trip samples, most recent last
+	latencySamples []time.Duration
+
+	// Output buffering counters
+	stats outputStats
+
+	// Input counters
+	inputStats inputStats
+
+	// Recent session output consumed by Expect
+	outputMu     sync.Mutex
+	outputBuf    []byte
+	outputNotify chan struct{} // nil while nothing waits for output
+
+	// Output received before the first game launch
+	bannerMu    sync.Mutex
+	banner      []byte
+	gameStarted bool
+
+	// Set while a welcome message is on screen
+	welcomePending atomic.Bool
 
 	// Channels for communication
-	done   chan struct{}
-	errors chan error
+	done        chan struct{}
+	errors      chan error
+	reconnectCh chan struct{}
 }
 
 // NewClient creates a new dgamelaunch client
@@ -78,19 +246,37 @@ func NewClient(config *ClientConfig) *Client {
 	if config == nil {
 		config = DefaultClientConfig()
 	}
+	if config.RequestCompression && config.Debug {
+		fmt.Fprintln(os.Stderr, "SSH compression requested but not supported by the transport; continuing uncompressed")
+	}
 
 	return &Client{
-		config: config,
-		done:   make(chan struct{}),
-		errors: make(chan error, 10),
+		config:      config,
+		done:        make(chan struct{}),
+		errors:      make(chan error, 10),
+		reconnectCh: make(chan struct{}, 1),
 	}
 }
 
 // Disconnect closes the connection to the server
 func (c *Client) Disconnect() error {
+	c.mu.RLock()
+	wasConnected := c.connected
+	c.mu.RUnlock()
+
+	err := c.disconnect()
+	if wasConnected {
+		c.emitEvent(ConnectionEvent{State: StateDisconnected})
+	}
+	return err
+}
+
+func (c *Client) disconnect() error {
 	c.mu.Lock()
 	defer c.mu.Unlock()
 
+	c.games = nil
+
 	if !c.connected {
 		return nil
 	}
@@ -136,35 +322,265 @@ func (c *Client) SetView(view View) error {
 		return fmt.Errorf("failed to initialize view: %w", err)
 	}
 
+	if err := c.showWelcome(view); err != nil {
+		return fmt.Errorf("failed to render welcome message: %w", err)
+	}
+
 	return nil
 }
 
-// SelectGame sends commands to select a specific game
-func (c *Client) SelectGame(gameName string) error {
-	c.mu.RLock()
-	session := c.session
-	c.mu.RUnlock()
-
-	if session == nil {
-		return ErrSessionNotStarted
+// DefaultSelectGameTimeout bounds SelectGame when ctx has no deadline
+const DefaultSelectGameTimeout = 10 * time.Second
+
+// gameMenuEntry matches a dgamelaunch menu entry such as "p) Play NetHack"
+// or "1) NetHack 3.6". A key in parentheses, as in "(c) Copyright", is not
+// an entry.
+var gameMenuEntry = regexp.MustCompile(`(?:^|[^(])([a-zA-Z0-9])\) +([^\x1b\r\n]+)`)
+
+// gameMenuIdle is how long the menu must go without redrawing before its
+// entries are read, so a menu sent in several chunks is seen whole
+const gameMenuIdle = 100 * time.Millisecond
+
+// menuVerbs are the leading words of menu entries that act on a game rather
+// than name it; "play" entries rank above the others
+var menuVerbs = map[string]bool{"play": true, "edit": true, "watch": true, "view": true, "change": true}
+
+// SelectGame waits for the game menu, sends the menu key of the entry
+// matching gameName and waits for the screen to change, confirming that the
+// game launched. It runs alongside Run, which feeds session output to it.
+// Output after the key press is game output and not part of LastBanner.
+func (c *Client) SelectGame(ctx context.Context, gameName string) error {
+	timeout := DefaultSelectGameTimeout
+	if deadline, ok := ctx.Deadline(); ok {
+		timeout = time.Until(deadline)
 	}
+	start := time.Now()
 
-	stdin, err := session.StdinPipe()
+	menu, err := c.waitGameMenu(ctx, timeout)
+	if errors.Is(err, ErrExpectTimeout) {
+		return fmt.Errorf("%w: no game menu appeared", ErrGameNotFound)
+	}
 	if err != nil {
 		return err
 	}
 
+	var game GameInfo
+	best := 0
+	for _, entry := range menu {
+		if rank := entry.matchRank(gameName); rank > best {
+			game, best = entry, rank
+		}
+	}
+	if best == 0 {
+		return fmt.Errorf("%w: %q is not on the game menu", ErrGameNotFound, gameName)
+	}
+
+	if err := c.Send([]byte(game.Command)); err != nil {
+		return fmt.Errorf("%w: %v", ErrGameSelectionFailed, err)
+	}
+	c.markGameStarted()
+
+	// Any redraw after the key press indicates the menu was left
+	err = c.waitOutput(ctx, timeout-time.Since(start), func(buf []byte) (int, bool) {
+		return len(buf), len(buf) > 0
+	})
+	if errors.Is(err, ErrExpectTimeout) {
+		return fmt.Errorf("%w: no response after selecting %q", ErrGameSelectionFailed, game.Description)
+	}
+	return err
+}
+
+// SelectGameRaw writes gameName followed by a newline to the session without
+// waiting for a menu or confirming the launch
+func (c *Client) SelectGameRaw(gameName string) error {
 	// Send game selection command
 	// This is server-specific and might need customization
-	_, err = fmt.Fprintf(stdin, "%s\n", gameName)
-	return err
+	if err := c.Send([]byte(gameName + "\n")); err != nil {
+		return err
+	}
+	c.markGameStarted()
+	return nil
+}
+
+// GameMenu waits for a dgamelaunch game menu in the session output and
+// returns its entries once the menu has finished drawing
+func (c *Client) GameMenu(ctx context.Context, timeout time.Duration) ([]GameInfo, error) {
+	games, err := c.waitGameMenu(ctx, timeout)
+	if errors.Is(err, ErrExpectTimeout) {
+		return nil, fmt.Errorf("%w after %v waiting for the game menu", ErrExpectTimeout, timeout)
+	}
+	return games, err
+}
+
+// waitGameMenu waits for a menu entry to appear, then for the output to
+// stay quiet for gameMenuIdle or until the timeout, and consumes the output
+// read so far. It returns the menu entries found in it.
+func (c *Client) waitGameMenu(ctx context.Context, timeout time.Duration) ([]GameInfo, error) {
+	deadline := time.Now().Add(timeout)
+	seen := 0
+	err := c.waitOutput(ctx, timeout, func(buf []byte) (int, bool) {
+		seen = len(buf)
+		return 0, gameMenuEntry.Match(buf)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	for {
+		wait := min(gameMenuIdle, time.Until(deadline))
+		if wait <= 0 {
+			break
+		}
+		err := c.waitOutput(ctx, wait, func(buf []byte) (int, bool) {
+			grew := len(buf) != seen
+			seen = len(buf)
+			return 0, grew
+		})
+		if errors.Is(err, ErrExpectTimeout) {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	c.outputMu.Lock()
+	defer c.outputMu.Unlock()
+
+	games := parseGameMenu(c.outputBuf)
+	c.outputBuf = c.outputBuf[:0]
+	return games, nil
+}
+
+// parseGameMenu returns the game menu entries found in data
+func parseGameMenu(data []byte) []GameInfo {
+	var games []GameInfo
+	for _, loc := range gameMenuEntry.FindAllSubmatchIndex(data, -1) {
+		games = append(games, menuGameInfo(string(data[loc[2]:loc[3]]), string(data[loc[4]:loc[5]])))
+	}
+	return games
+}
+
+// menuGameInfo builds a GameInfo from a menu key and its description. The
+// name is the first word after any verb, so "Play NetHack 3.6" is "nethack".
+func menuGameInfo(key, desc string) GameInfo {
+	desc = strings.TrimSpace(desc)
+	name := ""
+	for _, word := range strings.Fields(desc) {
+		word = strings.ToLower(word)
+		if !menuVerbs[word] {
+			name = word
+			break
+		}
+	}
+	return GameInfo{Name: name, Description: desc, Command: key, Available: true}
+}
+
+// matchRank scores how well a menu entry fits the named game, or returns 0
+// if the description does not mention it as a whole word. Entries that play
+// the game rank above ones that edit options or watch, and an exact name
+// above a passing mention.
+func (g GameInfo) matchRank(gameName string) int {
+	gameName = strings.ToLower(strings.TrimSpace(gameName))
+	if gameName == "" {
+		return 0
+	}
+	desc := strings.ToLower(g.Description)
+	if !containsWord(desc, gameName) {
+		return 0
+	}
+
+	rank := 1
+	if first := strings.Fields(desc)[0]; first == "play" || !menuVerbs[first] {
+		rank += 2
+	}
+	if g.Name == gameName {
+		rank++
+	}
+	return rank
+}
+
+// containsWord reports whether word occurs in s with no letter, digit or
+// underscore directly before or after it
+func containsWord(s, word string) bool {
+	for i := 0; i < len(s); {
+		j := strings.Index(s[i:], word)
+		if j < 0 {
+			return false
+		}
+		start, end := i+j, i+j+len(word)
+		before, _ := utf8.DecodeLastRuneInString(s[:start])
+		after, _ := utf8.DecodeRuneInString(s[end:])
+		if !isWordRune(before) && !isWordRune(after) {
+			return true
+		}
+		i = start + 1
+	}
+	return false
+}
+
+// isWordRune reports whether r is part of a word; utf8.RuneError, returned
+// at either end of the string, is not
+func isWordRune(r rune) bool {
+	return r != utf8.RuneError && (r == '_' || unicode.IsLetter(r) || unicode.IsDigit(r))
 }
 
 // Integration: Lines 161-178 (replace existing placeholder)
 // Context: Between SelectGame and keepAlive methods in Client struct
 
-// ListGames returns available games by querying the dgamelaunch server
-func (c *Client) ListGames() ([]GameInfo, error) {
+// Game list reads stop once the server has been silent for gameListIdle
+// after sending something, or after maxGameListSize bytes. Without a
+// deadline on the caller's context, the query gives up after
+// defaultGameListTimeout.
+const (
+	gameListIdle           = 200 * time.Millisecond
+	maxGameListSize        = 64 * 1024
+	defaultGameListTimeout = 10 * time.Second
+)
+
+// ListGames returns available games by querying the dgamelaunch server,
+// and caches the result for Games. If the server sends nothing before ctx
+// is done, the error wraps ErrGameListTimeout.
+func (c *Client) ListGames(ctx context.Context) ([]GameInfo, error) {
+	games, err := c.queryGames(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	c.mu.Lock()
+	c.games = games
+	c.mu.Unlock()
+	return games, nil
+}
+
+// Games returns a copy of the game list cached by ListGames or
+// RefreshGames, or nil if it has not been fetched. It never queries the
+// server, since that types into the running session.
+func (c *Client) Games() []GameInfo {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	if c.games == nil {
+		return nil
+	}
+	return append([]GameInfo(nil), c.games...)
+}
+
+// RefreshGames re-queries the server and replaces the cached game list.
+// The cache is left unchanged if the query fails or ctx is done first.
+func (c *Client) RefreshGames(ctx context.Context) error {
+	games, err := c.queryGames(ctx)
+	if err != nil {
+		return err
+	}
+
+	c.mu.Lock()
+	c.games = games
+	c.mu.Unlock()
+	return nil
+}
+
+// queryGames sends the list command and parses the server's response
+func (c *Client) queryGames(ctx context.Context) ([]GameInfo, error) {
 	c.mu.RLock()
 	session := c.session
 	c.mu.RUnlock()
@@ -189,15 +605,18 @@ func (c *Client) ListGames() ([]GameInfo, error) {
 		return nil, fmt.Errorf("failed to send list command: %w", err)
 	}
 
-	// Read response with timeout
-	response := make([]byte, 8192)
-	n, err := stdout.Read(response)
+	if _, ok := ctx.Deadline(); !ok {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, defaultGameListTimeout)
+		defer cancel()
+	}
+	response, err := readUntilIdle(ctx, stdout, gameListIdle, maxGameListSize)
 	if err != nil {
 		return nil, fmt.Errorf("failed to read game list response: %w", err)
 	}
 
 	// Parse the response for game entries
-	games, err := c.parseGameList(response[:n])
+	games, err := c.parseGameList(response)
 	if err != nil {
 		return nil, fmt.Errorf("failed to parse game list: %w", err)
 	}
@@ -205,39 +624,49 @@ func (c *Client) ListGames() ([]GameInfo, error) {
 	return games, nil
 }
 
-// parseGameList parses dgamelaunch server response to extract game information
-func (c *Client) parseGameList(data []byte) ([]GameInfo, error) {
-	lines := strings.Split(string(data), "\n")
-	var games []GameInfo
-
-	for _, line := range lines {
-		line = strings.TrimSpace(line)
-		if line == "" {
-			continue
-		}
-
-		// Common dgamelaunch format: "a) NetHack 3.6.7" or "b) DCSS 0.30"
-		if matches := regexp.MustCompile(`^([a-z])\)\s+(.+)`).FindStringSubmatch(line); len(matches) == 3 {
-			gameKey := matches[1]
-			gameDesc := matches[2]
+// readUntilIdle reads from r until it has been idle for the given duration
+// after the first data, returns an error, or max bytes have arrived. If ctx
+// is done first, whatever was read is returned, or an error wrapping
+// ErrGameListTimeout if nothing was. Session stdout is a stdoutReader, so
+// output arriving after the menu is left for the session's output loop.
+func readUntilIdle(ctx context.Context, r io.Reader, idle time.Duration, max int) ([]byte, error) {
+	sr, ok := r.(*stdoutReader)
+	if !ok {
+		sr = newStdoutReader(r)
+		defer sr.Close()
+	}
 
-			// Extract game name (first word) and description
-			parts := strings.Fields(gameDesc)
-			if len(parts) == 0 {
-				continue
+	var data []byte
+	var idleC <-chan time.Time
+	for {
+		chunk, ok, err := sr.next(ctx.Done(), idleC)
+		if !ok {
+			if len(data) == 0 {
+				return nil, fmt.Errorf("%w: %w", ErrGameListTimeout, ctx.Err())
 			}
+			return data, nil
+		}
 
-			gameName := strings.ToLower(parts[0])
-			gameInfo := GameInfo{
-				Name:        gameName,
-				Description: gameDesc,
-				Command:     gameKey,
-				Available:   true,
+		data = append(data, chunk...)
+		if len(data) >= max {
+			sr.unread(data[max:])
+			return data[:max], nil
+		}
+		if err != nil {
+			if len(data) == 0 {
+				return nil, err
 			}
-
-			games = append(games, gameInfo)
+			return data, nil
+		}
+		if len(data) > 0 {
+			idleC = time.After(idle)
 		}
 	}
+}
+
+// parseGameList parses dgamelaunch server response to extract game information
+func (c *Client) parseGameList(data []byte) ([]GameInfo, error) {
+	games := parseGameMenu(data)
 
 	// Return default games if parsing failed
 	if len(games) == 0 {
@@ -273,11 +702,14 @@ func (c *Client) keepAlive() {
 			c.mu.RUnlock()
 
 			if client != nil {
+				start := time.Now()
 				_, _, err := client.SendRequest("keepalive@openssh.com", true, nil)
 				if err != nil {
 					c.errors <- fmt.Errorf("keepalive failed: %w", err)
 					return
 				}
+				c.recordLatency(time.Since(start))
+				c.emitLatency()
 			}
 		case <-c.done:
 			return
@@ -285,6 +717,51 @@ func (c *Client) keepAlive() {
 	}
 }
 
+// RequestReconnect asks a running Run loop to drop the current session and
+// reconnect using the last authentication method. It does not block.
+func (c *Client) RequestReconnect() {
+	select {
+	case c.reconnectCh <- struct{}{}:
+	default:
+		// A reconnect is already pending
+	}
+}
+
+// latencyWindow is the number of keepalive samples averaged by Latency
+const latencyWindow = 8
+
+// recordLatency adds a keepalive round-trip sample
+func (c *Client) recordLatency(rtt time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.latencySamples = append(c.latencySamples, rtt)
+	if len(c.latencySamples) > latencyWindow {
+		c.latencySamples = c.latencySamples[1:]
+	}
+}
+
+// Latency returns the moving average of recent keepalive round-trip times,
+// or zero if no keepalive has completed yet
+func (c *Client) Latency() time.Duration {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.averageLatency()
+}
+
+// averageLatency must be called with c.mu held
+func (c *Client) averageLatency() time.Duration {
+	if len(c.latencySamples) == 0 {
+		return 0
+	}
+
+	var total time.Duration
+	for _, rtt := range c.latencySamples {
+		total += rtt
+	}
+	return total / time.Duration(len(c.latencySamples))
+}
+
 // Reconnect attempts to reconnect to the server
 func (c *Client) Reconnect(auth AuthMethod) error {
 	c.mu.Lock()
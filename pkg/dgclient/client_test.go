@@ -1,8 +1,16 @@
 package dgclient
 
 import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"reflect"
+	"strings"
 	"testing"
 	"time"
+
+	"golang.org/x/crypto/ssh"
 )
 
 func TestNewClient(t *testing.T) {
@@ -110,3 +118,623 @@ func (m *MockView) HandleInput() ([]byte, error) {
 func (m *MockView) Close() error {
 	return nil
 }
+
+func TestClientEventHandler(t *testing.T) {
+	client := NewClient(nil)
+	defer client.Close()
+	client.host = "example.com"
+	client.port = 2022
+
+	var got []ConnectionEvent
+	client.SetEventHandler(func(ev ConnectionEvent) {
+		got = append(got, ev)
+	})
+
+	client.emitEvent(ConnectionEvent{State: StateReconnecting, Attempt: 1, MaxAttempts: 3})
+
+	if len(got) != 1 {
+		t.Fatalf("Expected 1 event, got %d", len(got))
+	}
+	if got[0].Host != "example.com" || got[0].Port != 2022 {
+		t.Errorf("Expected event for example.com:2022, got %s:%d", got[0].Host, got[0].Port)
+	}
+	if got[0].State.String() != "reconnecting" {
+		t.Errorf("Expected state 'reconnecting', got %q", got[0].State)
+	}
+}
+
+func TestClientLatencyMovingAverage(t *testing.T) {
+	client := NewClient(nil)
+	defer client.Close()
+
+	if client.Latency() != 0 {
+		t.Errorf("Expected zero latency before any keepalive, got %v", client.Latency())
+	}
+
+	client.recordLatency(10 * time.Millisecond)
+	client.recordLatency(30 * time.Millisecond)
+	if got := client.Latency(); got != 20*time.Millisecond {
+		t.Errorf("Expected average latency 20ms, got %v", got)
+	}
+
+	// Old samples fall out of the window
+	for i := 0; i < latencyWindow; i++ {
+		client.recordLatency(50 * time.Millisecond)
+	}
+	if got := client.Latency(); got != 50*time.Millisecond {
+		t.Errorf("Expected windowed latency 50ms, got %v", got)
+	}
+}
+
+func TestLatencyGoesToViewOnly(t *testing.T) {
+	view := &recordingView{}
+	client := NewClient(nil)
+	defer client.Close()
+	if err := client.SetView(view); err != nil {
+		t.Fatalf("SetView() failed: %v", err)
+	}
+	var events int
+	client.SetEventHandler(func(ConnectionEvent) { events++ })
+
+	client.recordLatency(40 * time.Millisecond)
+	client.emitLatency()
+
+	if len(view.latencies) != 1 || view.latencies[0] != 40*time.Millisecond {
+		t.Errorf("Expected view to receive latency 40ms, got %v", view.latencies)
+	}
+	if events != 0 || len(view.events) != 0 {
+		t.Errorf("Expected no connection events for a latency update, got %d", events)
+	}
+}
+
+func TestConnectDialTimeout(t *testing.T) {
+	tests := []struct {
+		name        string
+		dialTimeout time.Duration
+		expected    time.Duration
+	}{
+		{"explicit", 2 * time.Second, 2 * time.Second},
+		{"fallback to connect timeout", 0, 7 * time.Second},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got time.Duration
+			config := DefaultClientConfig()
+			config.ConnectTimeout = 7 * time.Second
+			config.DialTimeout = tt.dialTimeout
+			config.SSHConfig = &ssh.ClientConfig{User: "test", HostKeyCallback: ssh.InsecureIgnoreHostKey()}
+			config.Dial = func(network, address string, timeout time.Duration) (net.Conn, error) {
+				got = timeout
+				return nil, errors.New("dial refused")
+			}
+
+			client := NewClient(config)
+			defer client.Close()
+
+			if err := client.Connect("example.com", 22, NewPasswordAuth("secret")); err == nil {
+				t.Fatal("Expected connection error")
+			}
+			if got != tt.expected {
+				t.Errorf("Expected dial timeout %v, got %v", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestConnectHandshakeTimeout(t *testing.T) {
+	config := DefaultClientConfig()
+	config.ConnectTimeout = time.Minute
+	config.HandshakeTimeout = 50 * time.Millisecond
+	config.SSHConfig = &ssh.ClientConfig{User: "test", HostKeyCallback: ssh.InsecureIgnoreHostKey()}
+	config.Dial = func(network, address string, timeout time.Duration) (net.Conn, error) {
+		// The server side accepts the connection but never speaks SSH
+		clientConn, serverConn := net.Pipe()
+		go io.Copy(io.Discard, serverConn)
+		return clientConn, nil
+	}
+
+	client := NewClient(config)
+	defer client.Close()
+
+	start := time.Now()
+	err := client.Connect("example.com", 22, NewPasswordAuth("secret"))
+	if err == nil {
+		t.Fatal("Expected handshake to time out")
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("Expected handshake to honor its own timeout, took %v", elapsed)
+	}
+}
+
+// statusMockView records connection events delivered to the view
+type statusMockView struct {
+	MockView
+	states []ConnectionState
+}
+
+func (m *statusMockView) HandleConnectionEvent(ev ConnectionEvent) {
+	m.states = append(m.states, ev.State)
+}
+
+func TestViewReceivesConnectionEvents(t *testing.T) {
+	config := DefaultClientConfig()
+	config.MaxReconnectAttempts = 2
+	config.ReconnectDelay = time.Millisecond
+	config.SSHConfig = &ssh.ClientConfig{User: "test", HostKeyCallback: ssh.InsecureIgnoreHostKey()}
+	config.Dial = func(network, address string, timeout time.Duration) (net.Conn, error) {
+		return nil, errors.New("connection refused")
+	}
+
+	client := NewClient(config)
+	defer client.Close()
+	view := &statusMockView{}
+	client.SetView(view)
+
+	client.emitEvent(ConnectionEvent{State: StateConnected})
+	client.handleReconnection(context.Background(), NewPasswordAuth("secret"), errors.New("connection reset"))
+
+	want := []ConnectionState{StateConnected, StateReconnecting, StateReconnecting, StateDisconnected}
+	if len(view.states) != len(want) {
+		t.Fatalf("Expected states %v, got %v", want, view.states)
+	}
+	for i := range want {
+		if view.states[i] != want[i] {
+			t.Errorf("Expected state %d to be %v, got %v", i, want[i], view.states[i])
+		}
+	}
+}
+
+const testGameMenu = "\x1b[2J\x1b[1;1H## dgamelaunch\r\n\x1b[3;1Hc) Play Crawl 0.30\x1b[4;1Hn) Play NetHack 3.6\r\n=> "
+
+func TestSelectGame(t *testing.T) {
+	client := NewClient(nil)
+	defer client.Close()
+	session := &fakeSession{}
+	client.session = session
+
+	go func() {
+		time.Sleep(10 * time.Millisecond)
+		client.recordOutput([]byte(testGameMenu))
+		// Wait for the key press before redrawing
+		for session.Stdin() == "" {
+			time.Sleep(time.Millisecond)
+		}
+		client.recordOutput([]byte("\x1b[2JNetHack, Copyright 1985-2023"))
+	}()
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	if err := client.SelectGame(ctx, "nethack"); err != nil {
+		t.Fatalf("SelectGame() failed: %v", err)
+	}
+	if got := session.Stdin(); got != "n" {
+		t.Errorf("Expected menu key %q, got %q", "n", got)
+	}
+}
+
+func TestSelectGameErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		game    string
+		wantErr error
+	}{
+		{"not on menu", "angband", ErrGameNotFound},
+		{"no launch", "crawl", ErrGameSelectionFailed},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := NewClient(nil)
+			defer client.Close()
+			client.session = &fakeSession{}
+			client.recordOutput([]byte(testGameMenu))
+
+			ctx, cancel := context.WithTimeout(context.Background(), 300*time.Millisecond)
+			defer cancel()
+
+			err := client.SelectGame(ctx, tt.game)
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("Expected %v, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestSelectGamePrefersPlayEntry(t *testing.T) {
+	tests := []struct {
+		name string
+		menu string
+		game string
+		want string
+	}{
+		{"options listed first", "o) Edit NetHack options\r\np) Play NetHack\r\n", "nethack", "p"},
+		{"digit keys", "(c) Copyright 2024\r\n1) NetHack 3.6\r\n2) Crawl 0.30\r\n", "crawl", "2"},
+		{"whole words only", "s) Play Slashem\r\nh) Play NetHack\r\n", "hack", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := NewClient(nil)
+			defer client.Close()
+			session := &fakeSession{}
+			client.session = session
+			client.recordOutput([]byte(tt.menu))
+
+			ctx, cancel := context.WithTimeout(context.Background(), 300*time.Millisecond)
+			defer cancel()
+			err := client.SelectGame(ctx, tt.game)
+
+			if tt.want == "" {
+				if !errors.Is(err, ErrGameNotFound) {
+					t.Errorf("Expected ErrGameNotFound, got %v", err)
+				}
+				return
+			}
+			if got := session.Stdin(); got != tt.want {
+				t.Errorf("Expected menu key %q, got %q", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestParseGameListMatchesMenu(t *testing.T) {
+	client := NewClient(nil)
+	defer client.Close()
+
+	menu := []byte("(c) Copyright 2024\r\n1) Play NetHack 3.7\r\nW) Watch games\r\n")
+	games, err := client.parseGameList(menu)
+	if err != nil {
+		t.Fatalf("parseGameList() failed: %v", err)
+	}
+	if want := parseGameMenu(menu); !reflect.DeepEqual(games, want) {
+		t.Errorf("Expected ListGames to see the menu entries %+v, got %+v", want, games)
+	}
+	if len(games) != 2 || games[0].Name != "nethack" || games[1].Command != "W" {
+		t.Errorf("Unexpected entries: %+v", games)
+	}
+}
+
+func TestContainsWord(t *testing.T) {
+	tests := []struct {
+		s, word string
+		want    bool
+	}{
+		{"play nethack 3.7", "nethack", true},
+		{"play slashem", "hack", false},
+		{"nethack", "nethack", true},
+		{"hack hack_x hack", "hack", true},
+		{"hack_x", "hack", false},
+		{"jeux·nethack", "nethack", true},
+		{"énethack", "nethack", false},
+	}
+	for _, tt := range tests {
+		if got := containsWord(tt.s, tt.word); got != tt.want {
+			t.Errorf("containsWord(%q, %q) = %v, want %v", tt.s, tt.word, got, tt.want)
+		}
+	}
+}
+
+func TestGameMenuWaitsForWholeMenu(t *testing.T) {
+	client := NewClient(nil)
+	defer client.Close()
+
+	go func() {
+		client.recordOutput([]byte("a) Play NetHack 3.6\r\n"))
+		time.Sleep(30 * time.Millisecond)
+		client.recordOutput([]byte("b) Play Crawl 0.30\r\n"))
+	}()
+
+	games, err := client.GameMenu(context.Background(), time.Second)
+	if err != nil {
+		t.Fatalf("GameMenu() failed: %v", err)
+	}
+	if len(games) != 2 || games[0].Name != "nethack" || games[1].Name != "crawl" {
+		t.Errorf("Expected both entries named after their games, got %+v", games)
+	}
+}
+
+func TestGameMenu(t *testing.T) {
+	client := NewClient(nil)
+	defer client.Close()
+	client.recordOutput([]byte(testGameMenu))
+
+	games, err := client.GameMenu(context.Background(), time.Second)
+	if err != nil {
+		t.Fatalf("GameMenu() failed: %v", err)
+	}
+	if len(games) != 2 {
+		t.Fatalf("Expected 2 games, got %d: %+v", len(games), games)
+	}
+	if games[1].Command != "n" || games[1].Description != "Play NetHack 3.6" {
+		t.Errorf("Unexpected menu entry: %+v", games[1])
+	}
+}
+
+func TestSelectGameRaw(t *testing.T) {
+	client := NewClient(nil)
+	defer client.Close()
+
+	if err := client.SelectGameRaw("nethack"); !errors.Is(err, ErrSessionNotStarted) {
+		t.Errorf("Expected ErrSessionNotStarted, got %v", err)
+	}
+
+	session := &fakeSession{}
+	client.session = session
+	if err := client.SelectGameRaw("nethack"); err != nil {
+		t.Fatalf("SelectGameRaw() failed: %v", err)
+	}
+	if got := session.Stdin(); got != "nethack\n" {
+		t.Errorf("Expected %q, got %q", "nethack\n", got)
+	}
+}
+
+func TestRequestCompressionReportedInStats(t *testing.T) {
+	config := DefaultClientConfig()
+	config.RequestCompression = true
+	client := NewClient(config)
+	defer client.Close()
+
+	if client.OutputStats().Compressed {
+		t.Error("Expected compression to be reported as not negotiated")
+	}
+}
+
+func TestListGamesTimesOutOnSilentServer(t *testing.T) {
+	pr, pw := io.Pipe()
+	defer pw.Close()
+	client := NewClient(nil)
+	defer client.Close()
+	client.session = &fakeSession{stdout: pr}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	_, err := client.ListGames(ctx)
+	if !errors.Is(err, ErrGameListTimeout) {
+		t.Fatalf("Expected ErrGameListTimeout, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("Expected ListGames to give up at the deadline, took %v", elapsed)
+	}
+}
+
+func TestListGamesReadsUntilIdle(t *testing.T) {
+	pr, pw := io.Pipe()
+	defer pw.Close()
+	client := NewClient(nil)
+	defer client.Close()
+	client.session = &fakeSession{stdout: pr}
+
+	// The menu arrives in two writes and the connection then stays open
+	go func() {
+		pw.Write([]byte("a) NetHack 3.6.7\n"))
+		time.Sleep(20 * time.Millisecond)
+		pw.Write([]byte("b) DCSS 0.30\n"))
+	}()
+
+	games, err := client.ListGames(context.Background())
+	if err != nil {
+		t.Fatalf("ListGames() failed: %v", err)
+	}
+	if len(games) != 2 {
+		t.Errorf("Expected both games, got %v", games)
+	}
+}
+
+func TestListGamesLeavesLaterOutput(t *testing.T) {
+	pr, pw := io.Pipe()
+	defer pw.Close()
+	stdout := newStdoutReader(pr)
+	defer stdout.Close()
+	client := NewClient(nil)
+	defer client.Close()
+	client.session = &fakeSession{stdout: stdout}
+
+	go pw.Write([]byte("a) NetHack 3.6.7\n"))
+	if _, err := client.ListGames(context.Background()); err != nil {
+		t.Fatalf("ListGames() failed: %v", err)
+	}
+
+	// Output sent after the menu went idle belongs to the session
+	go pw.Write([]byte("You see here a scroll."))
+	buf := make([]byte, 64)
+	n, err := stdout.Read(buf)
+	if err != nil || string(buf[:n]) != "You see here a scroll." {
+		t.Errorf("Expected later output to reach the next reader, got %q (%v)", buf[:n], err)
+	}
+}
+
+func TestStdoutReaderKeepsUnreadData(t *testing.T) {
+	stdout := newStdoutReader(strings.NewReader("abcdef"))
+	defer stdout.Close()
+
+	data, err := readUntilIdle(context.Background(), stdout, time.Second, 4)
+	if err != nil || string(data) != "abcd" {
+		t.Fatalf("Expected capped read %q, got %q (%v)", "abcd", data, err)
+	}
+	rest, err := io.ReadAll(stdout)
+	if err != nil || string(rest) != "ef" {
+		t.Errorf("Expected bytes past the cap to stay readable, got %q (%v)", rest, err)
+	}
+}
+
+// endlessReader returns a full buffer of game output on every read
+type endlessReader struct{}
+
+func (endlessReader) Read(p []byte) (int, error) {
+	for i := range p {
+		p[i] = 'x'
+	}
+	return len(p), nil
+}
+
+func TestStdoutReaderReadsDirectly(t *testing.T) {
+	stdout := newStdoutReader(endlessReader{})
+	defer stdout.Close()
+
+	buf := make([]byte, 4096)
+	if allocs := testing.AllocsPerRun(100, func() { stdout.Read(buf) }); allocs != 0 {
+		t.Errorf("Expected no allocations per read, got %v", allocs)
+	}
+}
+
+func BenchmarkStdoutRead(b *testing.B) {
+	for _, bc := range []struct {
+		name string
+		r    io.Reader
+	}{
+		{"direct", endlessReader{}},
+		{"stdoutReader", newStdoutReader(endlessReader{})},
+	} {
+		b.Run(bc.name, func(b *testing.B) {
+			buf := make([]byte, 4096)
+			b.SetBytes(int64(len(buf)))
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				bc.r.Read(buf)
+			}
+		})
+	}
+}
+
+func TestReadUntilIdleCapsSize(t *testing.T) {
+	data, err := readUntilIdle(context.Background(), strings.NewReader(strings.Repeat("x", 100)), time.Second, 10)
+	if err != nil {
+		t.Fatalf("readUntilIdle() failed: %v", err)
+	}
+	if len(data) != 10 {
+		t.Errorf("Expected 10 bytes, got %d", len(data))
+	}
+}
+
+func TestGamesCachesList(t *testing.T) {
+	session := &fakeSession{stdout: strings.NewReader("a) NetHack 3.6.7\nb) DCSS 0.30\n")}
+	client := NewClient(nil)
+	defer client.Close()
+	client.session = session
+
+	// Games never queries the server itself
+	if games := client.Games(); games != nil {
+		t.Errorf("Expected no games before a query, got %v", games)
+	}
+	if got := session.Stdin(); got != "" {
+		t.Errorf("Expected Games to send nothing, got %q", got)
+	}
+
+	if _, err := client.ListGames(context.Background()); err != nil {
+		t.Fatalf("ListGames() failed: %v", err)
+	}
+	games := client.Games()
+	if len(games) != 2 || games[0].Name != "nethack" {
+		t.Fatalf("Expected nethack and dcss, got %v", games)
+	}
+
+	if again := client.Games(); len(again) != 2 {
+		t.Errorf("Expected cached games, got %v", again)
+	}
+	if got := session.Stdin(); got != "l\n" {
+		t.Errorf("Expected a single list command, got %q", got)
+	}
+
+	// A failed refresh keeps the cached list
+	if err := client.RefreshGames(context.Background()); err == nil {
+		t.Error("Expected refresh to fail once the server stops responding")
+	}
+	if got := session.Stdin(); got != "l\nl\n" {
+		t.Errorf("Expected refresh to re-send the list command, got %q", got)
+	}
+	if cached := client.Games(); len(cached) != 2 {
+		t.Errorf("Expected cached games after failed refresh, got %v", cached)
+	}
+
+	client.Disconnect()
+	client.mu.RLock()
+	cached := client.games
+	client.mu.RUnlock()
+	if cached != nil {
+		t.Errorf("Expected cache to be cleared on disconnect, got %v", cached)
+	}
+}
+
+func TestTerminalModes(t *testing.T) {
+	config := DefaultClientConfig()
+	if modes := config.terminalModes(); modes[ssh.ECHO] != 1 {
+		t.Errorf("Expected default modes to enable ECHO, got %v", modes)
+	} else if _, ok := modes[ssh.IXON]; ok {
+		t.Errorf("Expected IXON to be left to the server by default, got %v", modes)
+	}
+
+	config.TerminalModes = ssh.TerminalModes{ssh.ECHO: 0, ssh.VERASE: 127}
+	config.DisableFlowControl = true
+	modes := config.terminalModes()
+	if modes[ssh.ECHO] != 0 || modes[ssh.VERASE] != 127 {
+		t.Errorf("Expected configured modes to override defaults, got %v", modes)
+	}
+	if v, ok := modes[ssh.IXON]; !ok || v != 0 {
+		t.Errorf("Expected IXON disabled, got %v", modes)
+	}
+	if v, ok := modes[ssh.IXOFF]; !ok || v != 0 {
+		t.Errorf("Expected IXOFF disabled, got %v", modes)
+	}
+	if _, ok := DefaultTerminalModes()[ssh.IXON]; ok {
+		t.Error("Expected DefaultTerminalModes to be unaffected")
+	}
+}
+
+func TestReconnectScheduledEvents(t *testing.T) {
+	config := DefaultClientConfig()
+	config.MaxReconnectAttempts = 3
+	config.ReconnectDelay = 10 * time.Millisecond
+	config.SSHConfig = &ssh.ClientConfig{User: "test", HostKeyCallback: ssh.InsecureIgnoreHostKey()}
+	config.Dial = func(network, address string, timeout time.Duration) (net.Conn, error) {
+		return nil, errors.New("connection refused")
+	}
+
+	client := NewClient(config)
+	defer client.Close()
+	var got []ConnectionEvent
+	client.SetEventHandler(func(ev ConnectionEvent) { got = append(got, ev) })
+
+	client.handleReconnection(context.Background(), NewPasswordAuth("secret"), errors.New("connection reset"))
+
+	wantDelays := []time.Duration{0, 10 * time.Millisecond, 15 * time.Millisecond}
+	if len(got) != len(wantDelays)+1 {
+		t.Fatalf("Expected %d events, got %d", len(wantDelays)+1, len(got))
+	}
+	for i, want := range wantDelays {
+		ev := got[i]
+		if ev.State != StateReconnecting || ev.Attempt != i+1 || ev.MaxAttempts != 3 || ev.Delay != want {
+			t.Errorf("Event %d: expected attempt %d/3 in %v, got %+v", i, i+1, want, ev)
+		}
+	}
+}
+
+func TestReconnectCancelledDuringDelay(t *testing.T) {
+	config := DefaultClientConfig()
+	config.ReconnectDelay = time.Hour
+	config.SSHConfig = &ssh.ClientConfig{User: "test", HostKeyCallback: ssh.InsecureIgnoreHostKey()}
+	config.Dial = func(network, address string, timeout time.Duration) (net.Conn, error) {
+		return nil, errors.New("connection refused")
+	}
+
+	client := NewClient(config)
+	defer client.Close()
+	ctx, cancel := context.WithCancel(context.Background())
+	var last ConnectionEvent
+	client.SetEventHandler(func(ev ConnectionEvent) {
+		last = ev
+		if ev.Delay > 0 {
+			cancel()
+		}
+	})
+
+	err := client.handleReconnection(ctx, NewPasswordAuth("secret"), errors.New("connection reset"))
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected context.Canceled, got %v", err)
+	}
+	if last.State != StateDisconnected || !errors.Is(last.Err, context.Canceled) {
+		t.Errorf("Expected cancellation reported as disconnect, got %+v", last)
+	}
+}
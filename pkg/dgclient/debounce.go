@@ -0,0 +1,46 @@
+package dgclient
+
+import (
+	"bytes"
+	"time"
+)
+
+// inputDebouncer drops repeats of the same key arriving within a window of
+// the last one sent, capping auto-repeat to one event per window. Only keys
+// in the configured set are debounced; other input always passes and resets
+// the repeat state.
+type inputDebouncer struct {
+	window time.Duration
+	keys   map[string]bool
+	now    func() time.Time
+
+	last   []byte
+	lastAt time.Time
+}
+
+// newInputDebouncer creates a debouncer for the given keys. An empty key
+// set debounces nothing, so typed text never loses double letters.
+func newInputDebouncer(window time.Duration, keys []string) *inputDebouncer {
+	d := &inputDebouncer{window: window, now: time.Now, keys: make(map[string]bool, len(keys))}
+	for _, k := range keys {
+		d.keys[k] = true
+	}
+	return d
+}
+
+// allow reports whether input should be sent
+func (d *inputDebouncer) allow(input []byte) bool {
+	if !d.keys[string(input)] {
+		d.last = d.last[:0]
+		return true
+	}
+
+	now := d.now()
+	if bytes.Equal(input, d.last) && now.Sub(d.lastAt) < d.window {
+		return false
+	}
+
+	d.last = append(d.last[:0], input...)
+	d.lastAt = now
+	return true
+}
@@ -0,0 +1,86 @@
+package dgclient
+
+import (
+	"testing"
+	"time"
+)
+
+func TestInputDebouncerCollapsesRepeats(t *testing.T) {
+	now := time.Unix(0, 0)
+	d := newInputDebouncer(100*time.Millisecond, []string{"h", "\x1b[A"})
+	d.now = func() time.Time { return now }
+
+	steps := []struct {
+		advance time.Duration
+		input   string
+		want    bool
+	}{
+		{0, "h", true},
+		{30 * time.Millisecond, "h", false},     // auto-repeat within window
+		{30 * time.Millisecond, "h", false},     // still within window of last sent
+		{50 * time.Millisecond, "h", true},      // window elapsed
+		{10 * time.Millisecond, "\x1b[A", true}, // different key
+		{10 * time.Millisecond, "\x1b[A", false},
+		{10 * time.Millisecond, "a", true}, // typing is never debounced
+		{10 * time.Millisecond, "a", true},
+		{10 * time.Millisecond, "\x1b[A", true}, // other input resets the repeat
+	}
+
+	for i, step := range steps {
+		now = now.Add(step.advance)
+		if got := d.allow([]byte(step.input)); got != step.want {
+			t.Errorf("Step %d: allow(%q) = %v, want %v", i, step.input, got, step.want)
+		}
+	}
+}
+
+func TestInputDebouncerNoKeys(t *testing.T) {
+	now := time.Unix(0, 0)
+	d := newInputDebouncer(50*time.Millisecond, nil)
+	d.now = func() time.Time { return now }
+
+	for i := 0; i < 2; i++ {
+		if !d.allow([]byte("o")) {
+			t.Errorf("Key %d: Expected nothing to be debounced with an empty key set", i)
+		}
+		now = now.Add(10 * time.Millisecond)
+	}
+}
+
+func TestRunSessionDebouncesRepeats(t *testing.T) {
+	config := DefaultClientConfig()
+	config.RepeatWindow = time.Hour
+	config.RepeatKeys = []string{"j"}
+	view := newScriptedView("j", "j", "j", "k", "k")
+
+	session := runFakeSession(t, config, view, "")
+
+	if got := session.Stdin(); got != "jkk" {
+		t.Errorf("Expected debounced input %q, got %q", "jkk", got)
+	}
+}
+
+func TestRunSessionInputStats(t *testing.T) {
+	config := DefaultClientConfig()
+	config.RepeatWindow = time.Hour
+	config.RepeatKeys = []string{"j"}
+	config.InputFilter = func(data []byte) []byte {
+		if string(data) == "x" {
+			return nil
+		}
+		return data
+	}
+	view := newScriptedView("j", "j", "j", "x", "k")
+
+	client := NewClient(config)
+	defer client.Close()
+	runFakeClient(t, client, view, "hello")
+
+	stats := client.InputStats()
+	if stats.Events != 5 || stats.Debounced != 2 || stats.Filtered != 1 || stats.Sent != 2 {
+		t.Errorf("Expected 5 events, 2 debounced, 1 filtered, 2 sent, got %+v", stats)
+	}
+	if got := client.OutputStats().RenderedBytes; got != 5 {
+		t.Errorf("Expected 5 rendered bytes, got %d", got)
+	}
+}
@@ -11,19 +11,26 @@ var (
 	ErrAuthenticationFailed = errors.New("authentication failed")
 	ErrHostKeyMismatch      = errors.New("host key mismatch")
 	ErrConnectionTimeout    = errors.New("connection timeout")
+	ErrInvalidTarget        = errors.New("invalid connection target")
 
 	// Session errors
 	ErrPTYAllocationFailed = errors.New("PTY allocation failed")
 	ErrSessionNotStarted   = errors.New("session not started")
 	ErrInvalidTerminalSize = errors.New("invalid terminal size")
+	ErrReconnectRequested  = errors.New("reconnect requested")
 
 	// View errors
-	ErrViewNotSet     = errors.New("view not set")
-	ErrViewInitFailed = errors.New("view initialization failed")
+	ErrViewNotSet        = errors.New("view not set")
+	ErrViewInitFailed    = errors.New("view initialization failed")
+	ErrViewNotRegistered = errors.New("unknown view")
 
 	// Game errors
 	ErrGameNotFound        = errors.New("game not found")
 	ErrGameSelectionFailed = errors.New("game selection failed")
+	ErrGameListTimeout     = errors.New("game list timed out")
+
+	// Script errors
+	ErrExpectTimeout = errors.New("expect timed out")
 )
 
 // ConnectionError wraps connection-specific errors with additional context
@@ -54,3 +61,37 @@ func (e *AuthError) Error() string {
 func (e *AuthError) Unwrap() error {
 	return e.Err
 }
+
+// ScriptError identifies the login script step that failed
+type ScriptError struct {
+	Step   int
+	Expect string
+	Err    error
+}
+
+func (e *ScriptError) Error() string {
+	return fmt.Sprintf("script step %d (expect %q) failed: %v", e.Step, e.Expect, e.Err)
+}
+
+func (e *ScriptError) Unwrap() error {
+	return e.Err
+}
+
+// ExitError reports that the remote game or shell exited with a non-zero
+// status or was killed by a signal
+type ExitError struct {
+	Status int
+	Signal string
+	Err    error
+}
+
+func (e *ExitError) Error() string {
+	if e.Signal != "" {
+		return fmt.Sprintf("remote session killed by signal %s", e.Signal)
+	}
+	return fmt.Sprintf("remote session exited with status %d", e.Status)
+}
+
+func (e *ExitError) Unwrap() error {
+	return e.Err
+}
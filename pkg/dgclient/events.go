@@ -0,0 +1,93 @@
+package dgclient
+
+import "time"
+
+// ConnectionState describes the client's connection status
+type ConnectionState int
+
+const (
+	StateDisconnected ConnectionState = iota
+	StateConnected
+	StateReconnecting
+)
+
+// String returns a human-readable name for the state
+func (s ConnectionState) String() string {
+	switch s {
+	case StateConnected:
+		return "connected"
+	case StateReconnecting:
+		return "reconnecting"
+	default:
+		return "disconnected"
+	}
+}
+
+// ConnectionEvent reports a change in connection status
+type ConnectionEvent struct {
+	State ConnectionState
+	Host  string
+	Port  int
+
+	// Reconnection progress, set for StateReconnecting. Delay is the wait
+	// before this attempt starts (zero for the first attempt).
+	Attempt     int
+	MaxAttempts int
+	Delay       time.Duration
+
+	// Most recent round-trip time, if known
+	Latency time.Duration
+
+	// Cause of a disconnect, if any
+	Err error
+}
+
+// EventHandler receives connection events. Handlers are called synchronously
+// from the goroutine that caused the change and must not block.
+type EventHandler func(ConnectionEvent)
+
+// SetEventHandler registers a handler for connection events
+func (c *Client) SetEventHandler(handler EventHandler) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.eventHandler = handler
+}
+
+// emitEvent fills in connection details and delivers ev to the handler and
+// to the view if it implements ConnectionStatusView.
+// It must be called without holding c.mu.
+func (c *Client) emitEvent(ev ConnectionEvent) {
+	c.viewMu.RLock()
+	statusView, _ := c.view.(ConnectionStatusView)
+	c.viewMu.RUnlock()
+
+	c.mu.RLock()
+	handler := c.eventHandler
+	ev.Host = c.host
+	ev.Port = c.port
+	if ev.Latency == 0 {
+		ev.Latency = c.averageLatency()
+	}
+	c.mu.RUnlock()
+
+	if handler != nil {
+		handler(ev)
+	}
+	if statusView != nil {
+		statusView.HandleConnectionEvent(ev)
+	}
+}
+
+// emitLatency passes the average round-trip time to the view if it
+// implements LatencyView. Latency updates are not connection events, so
+// event handlers only see state changes and can poll Latency instead.
+// It must be called without holding c.mu.
+func (c *Client) emitLatency() {
+	c.viewMu.RLock()
+	latencyView, _ := c.view.(LatencyView)
+	c.viewMu.RUnlock()
+
+	if latencyView != nil {
+		latencyView.HandleLatency(c.Latency())
+	}
+}
@@ -0,0 +1,50 @@
+package dgclient
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"time"
+)
+
+// activityReader signals activity whenever data is read from r
+type activityReader struct {
+	r        io.Reader
+	activity chan<- struct{}
+}
+
+func (r activityReader) Read(p []byte) (int, error) {
+	n, err := r.r.Read(p)
+	if n > 0 {
+		select {
+		case r.activity <- struct{}{}:
+		default:
+		}
+	}
+	return n, err
+}
+
+// idleRefresh sends IdleRefreshKeys each time IdleRefreshInterval passes
+// without server output, until the session ends
+func (c *Client) idleRefresh(ctx context.Context, send func([]byte) error, activity <-chan struct{}, done <-chan struct{}, errCh chan<- error) {
+	interval := c.config.IdleRefreshInterval
+	timer := time.NewTimer(interval)
+	defer timer.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-done:
+			return
+		case <-activity:
+			timer.Reset(interval)
+		case <-timer.C:
+			if err := send(c.config.IdleRefreshKeys); err != nil {
+				errCh <- fmt.Errorf("stdin write error: %w", err)
+				return
+			}
+			timer.Reset(interval)
+		}
+	}
+}
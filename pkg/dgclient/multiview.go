@@ -0,0 +1,115 @@
+package dgclient
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// multiView fans session output out to several views. The primary view
+// supplies input and the terminal size; the others only display.
+type multiView struct {
+	primary View
+	views   []View
+}
+
+// NewMultiView returns a View that shares one session between several
+// views, for example a local terminal and a remote spectator display.
+// Output, Clear and SetSize go to every view. Input and GetSize come from
+// primary, so only the primary view can play. Connection events and size
+// readiness are forwarded to the views that support them.
+func NewMultiView(primary View, others ...View) View {
+	views := make([]View, 0, 1+len(others))
+	views = append(views, primary)
+	views = append(views, others...)
+	return &multiView{primary: primary, views: views}
+}
+
+// Init initializes each view in order, closing those already initialized
+// if one fails
+func (m *multiView) Init() error {
+	for i, v := range m.views {
+		if err := v.Init(); err != nil {
+			for _, done := range m.views[:i] {
+				done.Close()
+			}
+			return fmt.Errorf("view %d: %w", i, err)
+		}
+	}
+	return nil
+}
+
+// Render passes data to every view. A failing view does not stop the
+// others from rendering.
+func (m *multiView) Render(data []byte) error {
+	return m.each(func(v View) error { return v.Render(data) })
+}
+
+func (m *multiView) Clear() error {
+	return m.each(View.Clear)
+}
+
+func (m *multiView) SetSize(width, height int) error {
+	return m.each(func(v View) error { return v.SetSize(width, height) })
+}
+
+func (m *multiView) GetSize() (width, height int) {
+	return m.primary.GetSize()
+}
+
+func (m *multiView) HandleInput() ([]byte, error) {
+	return m.primary.HandleInput()
+}
+
+func (m *multiView) Close() error {
+	return m.each(View.Close)
+}
+
+// HandleConnectionEvent forwards ev to each view showing connection status
+func (m *multiView) HandleConnectionEvent(ev ConnectionEvent) {
+	for _, v := range m.views {
+		if sv, ok := v.(ConnectionStatusView); ok {
+			sv.HandleConnectionEvent(ev)
+		}
+	}
+}
+
+// HandleLatency forwards latency to each view showing it
+func (m *multiView) HandleLatency(latency time.Duration) {
+	for _, v := range m.views {
+		if lv, ok := v.(LatencyView); ok {
+			lv.HandleLatency(latency)
+		}
+	}
+}
+
+// SizeReady reports the primary view's readiness, since its size is the
+// one requested for the PTY
+func (m *multiView) SizeReady() <-chan struct{} {
+	if sv, ok := m.primary.(SizeReadyView); ok {
+		return sv.SizeReady()
+	}
+	ready := make(chan struct{})
+	close(ready)
+	return ready
+}
+
+// Responses delivers the primary view's replies, since only its emulator
+// answers the game; it returns nil (never ready) if the primary has none
+func (m *multiView) Responses() <-chan []byte {
+	if rv, ok := m.primary.(ResponseView); ok {
+		return rv.Responses()
+	}
+	return nil
+}
+
+// each calls fn for every view and joins the errors
+func (m *multiView) each(fn func(View) error) error {
+	var errs []error
+	for i, v := range m.views {
+		if err := fn(v); err != nil {
+			errs = append(errs, fmt.Errorf("view %d: %w", i, err))
+		}
+	}
+	return errors.Join(errs...)
+}
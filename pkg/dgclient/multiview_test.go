@@ -0,0 +1,99 @@
+package dgclient
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+// recordingView records calls for MultiView tests
+type recordingView struct {
+	MockView
+
+	rendered  []string
+	closed    bool
+	events    []ConnectionEvent
+	latencies []time.Duration
+	renderErr error
+	w, h      int
+}
+
+func (v *recordingView) Render(data []byte) error {
+	v.rendered = append(v.rendered, string(data))
+	return v.renderErr
+}
+
+func (v *recordingView) GetSize() (int, int) { return v.w, v.h }
+
+func (v *recordingView) Close() error {
+	v.closed = true
+	return nil
+}
+
+func (v *recordingView) HandleConnectionEvent(ev ConnectionEvent) {
+	v.events = append(v.events, ev)
+}
+
+func (v *recordingView) HandleLatency(latency time.Duration) {
+	v.latencies = append(v.latencies, latency)
+}
+
+func TestMultiViewRendersToAll(t *testing.T) {
+	primary := &recordingView{w: 100, h: 30}
+	primary.InputData = []byte("k")
+	spectator := &recordingView{w: 80, h: 24, renderErr: errors.New("disconnected")}
+	third := &recordingView{}
+
+	mv := NewMultiView(primary, spectator, third)
+
+	err := mv.Render([]byte("hello"))
+	if err == nil {
+		t.Error("Expected the failing view's error to be returned")
+	}
+	for i, v := range []*recordingView{primary, spectator, third} {
+		if len(v.rendered) != 1 || v.rendered[0] != "hello" {
+			t.Errorf("View %d: expected render to reach it, got %q", i, v.rendered)
+		}
+	}
+
+	if w, h := mv.GetSize(); w != 100 || h != 30 {
+		t.Errorf("Expected primary size 100x30, got %dx%d", w, h)
+	}
+	if data, _ := mv.HandleInput(); string(data) != "k" {
+		t.Errorf("Expected input from primary, got %q", data)
+	}
+
+	mv.(ConnectionStatusView).HandleConnectionEvent(ConnectionEvent{State: StateConnected})
+	if len(primary.events) != 1 || len(third.events) != 1 {
+		t.Error("Expected connection events forwarded to every view")
+	}
+
+	select {
+	case <-mv.(SizeReadyView).SizeReady():
+	default:
+		t.Error("Expected size ready when the primary does not wait for its size")
+	}
+
+	if err := mv.Close(); err != nil {
+		t.Fatalf("Close() failed: %v", err)
+	}
+	if !primary.closed || !spectator.closed || !third.closed {
+		t.Error("Expected Close to close every view")
+	}
+}
+
+func TestClientRendersToMultiView(t *testing.T) {
+	a, b := &recordingView{}, &recordingView{}
+	config := DefaultClientConfig()
+	view := NewMultiView(a, b)
+
+	client := NewClient(config)
+	defer client.Close()
+	if err := client.SetView(view); err != nil {
+		t.Fatalf("SetView() failed: %v", err)
+	}
+	client.emitEvent(ConnectionEvent{State: StateConnected})
+	if len(a.events) != 1 || len(b.events) != 1 {
+		t.Errorf("Expected client events to reach both views, got %d and %d", len(a.events), len(b.events))
+	}
+}
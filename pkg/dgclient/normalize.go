@@ -0,0 +1,43 @@
+package dgclient
+
+import "golang.org/x/text/unicode/norm"
+
+// InputNormalization selects a Unicode normalization form applied to user
+// input before it is sent to the server
+type InputNormalization int
+
+const (
+	// NormalizeNone sends input bytes unchanged
+	NormalizeNone InputNormalization = iota
+	// NormalizeNFC composes characters, e.g. "e" + U+0301 becomes "é"
+	NormalizeNFC
+	// NormalizeNFD decomposes characters, e.g. "é" becomes "e" + U+0301
+	NormalizeNFD
+)
+
+// String returns the name of the normalization form
+func (n InputNormalization) String() string {
+	switch n {
+	case NormalizeNone:
+		return "none"
+	case NormalizeNFC:
+		return "NFC"
+	case NormalizeNFD:
+		return "NFD"
+	default:
+		return "unknown"
+	}
+}
+
+// apply normalizes data. Bytes that are not valid UTF-8, such as partial
+// sequences split across reads, are passed through unchanged.
+func (n InputNormalization) apply(data []byte) []byte {
+	switch n {
+	case NormalizeNFC:
+		return norm.NFC.Bytes(data)
+	case NormalizeNFD:
+		return norm.NFD.Bytes(data)
+	default:
+		return data
+	}
+}
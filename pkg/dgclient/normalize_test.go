@@ -0,0 +1,38 @@
+package dgclient
+
+import "testing"
+
+func TestInputNormalization(t *testing.T) {
+	const decomposed = "cafe\u0301"
+	const composed = "caf\u00e9"
+
+	tests := []struct {
+		form  InputNormalization
+		input string
+		want  string
+	}{
+		{NormalizeNone, decomposed, decomposed},
+		{NormalizeNFC, decomposed, composed},
+		{NormalizeNFD, composed, decomposed},
+		{NormalizeNFC, "\x1b[A", "\x1b[A"},
+		{NormalizeNFC, "\xc3", "\xc3"},
+	}
+
+	for _, tt := range tests {
+		if got := string(tt.form.apply([]byte(tt.input))); got != tt.want {
+			t.Errorf("%s.apply(%q) = %q, want %q", tt.form, tt.input, got, tt.want)
+		}
+	}
+}
+
+func TestRunSessionInputNormalization(t *testing.T) {
+	config := DefaultClientConfig()
+	config.InputNormalization = NormalizeNFC
+	view := newScriptedView("e\u0301")
+
+	session := runFakeSession(t, config, view, "")
+
+	if got := session.Stdin(); got != "\u00e9" {
+		t.Errorf("Expected composed %q, got %q", "\u00e9", got)
+	}
+}
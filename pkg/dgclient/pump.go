@@ -0,0 +1,162 @@
+package dgclient
+
+import (
+	"sync"
+	"sync/atomic"
+)
+
+// OutputStats reports how session output reached the view
+type OutputStats struct {
+	// Renders is the number of Render calls made
+	Renders uint64
+
+	// RenderedBytes is the total size of the data passed to Render; divided
+	// by Renders it gives the average frame size
+	RenderedBytes uint64
+
+	// DroppedFrames counts output chunks that were merged into a later
+	// render instead of being drawn on their own because the view fell behind
+	DroppedFrames uint64
+
+	// Stalls counts reads that waited for the view because the output
+	// buffer was full
+	Stalls uint64
+
+	// Compressed reports whether the SSH transport negotiated compression
+	Compressed bool
+}
+
+// InputStats reports what happened to input read from the view
+type InputStats struct {
+	// Events is the number of input events read from the view
+	Events uint64
+
+	// Debounced counts events dropped as key repeats
+	Debounced uint64
+
+	// Filtered counts events removed entirely by the input filter
+	Filtered uint64
+
+	// Sent is the number of writes made to the session's stdin, including
+	// idle refresh keys and data passed to Send
+	Sent uint64
+}
+
+// outputStats holds the live counters behind OutputStats
+type outputStats struct {
+	renders       atomic.Uint64
+	renderedBytes atomic.Uint64
+	droppedFrames atomic.Uint64
+	stalls        atomic.Uint64
+}
+
+// OutputStats returns output buffering counters for the client's sessions
+func (c *Client) OutputStats() OutputStats {
+	return OutputStats{
+		Renders:       c.stats.renders.Load(),
+		RenderedBytes: c.stats.renderedBytes.Load(),
+		DroppedFrames: c.stats.droppedFrames.Load(),
+		Stalls:        c.stats.stalls.Load(),
+		Compressed:    false, // x/crypto/ssh offers no compression algorithms
+	}
+}
+
+// inputStats holds the live counters behind InputStats
+type inputStats struct {
+	events    atomic.Uint64
+	debounced atomic.Uint64
+	filtered  atomic.Uint64
+	sent      atomic.Uint64
+}
+
+// InputStats returns input counters for the client's sessions
+func (c *Client) InputStats() InputStats {
+	return InputStats{
+		Events:    c.inputStats.events.Load(),
+		Debounced: c.inputStats.debounced.Load(),
+		Filtered:  c.inputStats.filtered.Load(),
+		Sent:      c.inputStats.sent.Load(),
+	}
+}
+
+// recordRender counts a frame about to be passed to Render
+func (s *outputStats) recordRender(frame []byte) {
+	s.renders.Add(1)
+	s.renderedBytes.Add(uint64(len(frame)))
+}
+
+// outputPump is a bounded buffer between the session reader and the view.
+// When the view is slower than the server, queued chunks are merged so the
+// next Render draws the latest state in one pass rather than replaying every
+// stale intermediate chunk. When the buffer is full the reader blocks,
+// applying backpressure to the SSH channel.
+type outputPump struct {
+	mu     sync.Mutex
+	cond   *sync.Cond
+	buf    []byte
+	chunks int
+	max    int
+	closed bool
+	stats  *outputStats
+}
+
+func newOutputPump(max int, stats *outputStats) *outputPump {
+	p := &outputPump{max: max, stats: stats}
+	p.cond = sync.NewCond(&p.mu)
+	return p
+}
+
+// push queues data, blocking while the buffer is full. It returns false if
+// the pump was closed.
+func (p *outputPump) push(data []byte) bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	stalled := false
+	for !p.closed && len(p.buf) > 0 && len(p.buf)+len(data) > p.max {
+		if !stalled {
+			stalled = true
+			p.stats.stalls.Add(1)
+		}
+		p.cond.Wait()
+	}
+	if p.closed {
+		return false
+	}
+
+	p.buf = append(p.buf, data...)
+	p.chunks++
+	p.cond.Broadcast()
+	return true
+}
+
+// pop returns all queued data as one frame, blocking until data is available.
+// It returns nil once the pump is closed and drained.
+func (p *outputPump) pop() []byte {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	for len(p.buf) == 0 && !p.closed {
+		p.cond.Wait()
+	}
+	if len(p.buf) == 0 {
+		return nil
+	}
+
+	frame := p.buf
+	if p.chunks > 1 {
+		p.stats.droppedFrames.Add(uint64(p.chunks - 1))
+	}
+	p.buf = nil
+	p.chunks = 0
+	p.cond.Broadcast()
+	return frame
+}
+
+// close wakes all waiters; pop still drains data queued before close
+func (p *outputPump) close() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.closed = true
+	p.cond.Broadcast()
+}
@@ -0,0 +1,80 @@
+package dgclient
+
+import (
+	"testing"
+	"time"
+)
+
+func TestOutputPumpCoalescesToKeyframe(t *testing.T) {
+	var stats outputStats
+	pump := newOutputPump(1024, &stats)
+
+	// View is stalled: several chunks queue up
+	pump.push([]byte("\x1b[H"))
+	pump.push([]byte("frame1"))
+	pump.push([]byte("frame2"))
+
+	frame := pump.pop()
+	if string(frame) != "\x1b[Hframe1frame2" {
+		t.Errorf("Expected queued chunks merged into one frame, got %q", frame)
+	}
+	if got := stats.droppedFrames.Load(); got != 2 {
+		t.Errorf("Expected 2 dropped frames, got %d", got)
+	}
+}
+
+func TestOutputPumpBackpressure(t *testing.T) {
+	var stats outputStats
+	pump := newOutputPump(8, &stats)
+
+	pump.push([]byte("12345678"))
+
+	pushed := make(chan bool)
+	go func() { pushed <- pump.push([]byte("9")) }()
+
+	select {
+	case <-pushed:
+		t.Fatal("Expected push to block while the buffer is full")
+	case <-time.After(20 * time.Millisecond):
+	}
+
+	if frame := pump.pop(); string(frame) != "12345678" {
+		t.Errorf("Expected first frame '12345678', got %q", frame)
+	}
+	if !<-pushed {
+		t.Error("Expected blocked push to succeed after pop")
+	}
+	if got := stats.stalls.Load(); got != 1 {
+		t.Errorf("Expected 1 stall, got %d", got)
+	}
+}
+
+func TestOutputPumpCloseDrains(t *testing.T) {
+	var stats outputStats
+	pump := newOutputPump(64, &stats)
+
+	pump.push([]byte("tail"))
+	pump.close()
+
+	if frame := pump.pop(); string(frame) != "tail" {
+		t.Errorf("Expected queued data to drain after close, got %q", frame)
+	}
+	if frame := pump.pop(); frame != nil {
+		t.Errorf("Expected nil after drain, got %q", frame)
+	}
+	if pump.push([]byte("late")) {
+		t.Error("Expected push after close to fail")
+	}
+}
+
+func TestRunSessionBufferedOutput(t *testing.T) {
+	config := DefaultClientConfig()
+	config.OutputBufferSize = 1024
+
+	view := newScriptedView()
+	runFakeSession(t, config, view, "buffered output")
+
+	if got := view.Rendered(); got != "buffered output" {
+		t.Errorf("Expected rendered output 'buffered output', got %q", got)
+	}
+}
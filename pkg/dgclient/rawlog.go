@@ -0,0 +1,87 @@
+package dgclient
+
+import (
+	"bufio"
+	"io"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// Raw log directions
+const (
+	rawLogOutput = '<'
+	rawLogInput  = '>'
+)
+
+// rawLogger writes session bytes to a debug log, one timestamped line per
+// read or write:
+//
+//	2006-01-02T15:04:05.000000Z07:00 < "\x1b[H\x1b[2Jhello"
+//
+// '<' marks bytes received from the server and '>' bytes sent to it.
+type rawLogger struct {
+	mu  sync.Mutex
+	f   *os.File
+	w   *bufio.Writer
+	now func() time.Time
+
+	closed bool
+}
+
+// openRawLog opens path for appending, so reconnected sessions continue the
+// same log
+func openRawLog(path string) (*rawLogger, error) {
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o600)
+	if err != nil {
+		return nil, err
+	}
+	return &rawLogger{f: f, w: bufio.NewWriter(f), now: time.Now}, nil
+}
+
+// log records data sent in the given direction. Each line is flushed as it
+// is written, so the log is complete up to the last read even if the client
+// crashes or is killed. Write errors are ignored so a full disk never
+// interrupts the session, and input still arriving after the session ended
+// is dropped.
+func (l *rawLogger) log(dir byte, data []byte) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.closed {
+		return
+	}
+
+	l.w.WriteString(l.now().Format("2006-01-02T15:04:05.000000Z07:00"))
+	l.w.WriteByte(' ')
+	l.w.WriteByte(dir)
+	l.w.WriteByte(' ')
+	l.w.WriteString(strconv.Quote(string(data)))
+	l.w.WriteByte('\n')
+	l.w.Flush()
+}
+
+// Close closes the file
+func (l *rawLogger) Close() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.closed {
+		return nil
+	}
+	l.closed = true
+	return l.f.Close()
+}
+
+// rawLogReader logs everything read from r as server output
+type rawLogReader struct {
+	r   io.Reader
+	log *rawLogger
+}
+
+func (r rawLogReader) Read(p []byte) (int, error) {
+	n, err := r.r.Read(p)
+	if n > 0 {
+		r.log.log(rawLogOutput, p[:n])
+	}
+	return n, err
+}
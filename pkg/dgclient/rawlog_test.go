@@ -0,0 +1,54 @@
+package dgclient
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRawLogRecordsSessionBytes(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "raw.log")
+	config := DefaultClientConfig()
+	config.RawLogPath = path
+	config.RawLogInput = true
+
+	runFakeSession(t, config, newScriptedView("k"), "hello\x1b[0m")
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("Failed to read raw log: %v", err)
+	}
+	log := string(data)
+	if !strings.Contains(log, ` < "hello\x1b[0m"`) {
+		t.Errorf("Expected output bytes in raw log, got %q", log)
+	}
+	if !strings.Contains(log, ` > "k"`) {
+		t.Errorf("Expected input bytes in raw log, got %q", log)
+	}
+}
+
+func TestRawLogFormat(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "raw.log")
+	l, err := openRawLog(path)
+	if err != nil {
+		t.Fatalf("openRawLog() failed: %v", err)
+	}
+	l.now = func() time.Time { return time.Date(2024, 1, 2, 3, 4, 5, 6000, time.UTC) }
+
+	l.log(rawLogOutput, []byte("a\r\n"))
+	want := "2024-01-02T03:04:05.000006Z < \"a\\r\\n\"\n"
+	if data, _ := os.ReadFile(path); string(data) != want {
+		t.Errorf("Expected line flushed before Close %q, got %q", want, data)
+	}
+	if err := l.Close(); err != nil {
+		t.Fatalf("Close() failed: %v", err)
+	}
+	l.log(rawLogOutput, []byte("late"))
+
+	data, _ := os.ReadFile(path)
+	if string(data) != want {
+		t.Errorf("Expected %q, got %q", want, data)
+	}
+}
@@ -0,0 +1,70 @@
+package dgclient
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+)
+
+var (
+	viewRegistryMu sync.RWMutex
+	viewRegistry   = make(map[string]ViewFactory)
+)
+
+// RegisterView makes a view factory available by name. Registering the same
+// name twice replaces the previous factory. It panics if factory is nil.
+func RegisterView(name string, factory ViewFactory) {
+	if factory == nil {
+		panic("dgclient: RegisterView factory is nil")
+	}
+
+	viewRegistryMu.Lock()
+	defer viewRegistryMu.Unlock()
+	viewRegistry[name] = factory
+}
+
+// LookupView returns the view factory registered under name
+func LookupView(name string) (ViewFactory, error) {
+	viewRegistryMu.RLock()
+	factory, ok := viewRegistry[name]
+	viewRegistryMu.RUnlock()
+
+	if !ok {
+		available := RegisteredViews()
+		if len(available) == 0 {
+			return nil, fmt.Errorf("%w: %q (no views registered)", ErrViewNotRegistered, name)
+		}
+		return nil, fmt.Errorf("%w: %q (available: %s)", ErrViewNotRegistered, name, strings.Join(available, ", "))
+	}
+
+	return factory, nil
+}
+
+// CreateView creates a view using the factory registered under name
+func CreateView(name string, opts ViewOptions) (View, error) {
+	factory, err := LookupView(name)
+	if err != nil {
+		return nil, err
+	}
+
+	view, err := factory.CreateView(opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create %s view: %w", name, err)
+	}
+
+	return view, nil
+}
+
+// RegisteredViews returns the sorted names of all registered views
+func RegisteredViews() []string {
+	viewRegistryMu.RLock()
+	defer viewRegistryMu.RUnlock()
+
+	names := make([]string, 0, len(viewRegistry))
+	for name := range viewRegistry {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
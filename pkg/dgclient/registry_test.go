@@ -0,0 +1,46 @@
+package dgclient
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestRegisterAndCreateView(t *testing.T) {
+	mock := &MockView{}
+	RegisterView("mock", ViewFactoryFunc(func(opts ViewOptions) (View, error) {
+		return mock, nil
+	}))
+
+	view, err := CreateView("mock", DefaultViewOptions())
+	if err != nil {
+		t.Fatalf("CreateView() failed: %v", err)
+	}
+	if view != mock {
+		t.Error("CreateView() did not return the registered view")
+	}
+
+	found := false
+	for _, name := range RegisteredViews() {
+		if name == "mock" {
+			found = true
+		}
+	}
+	if !found {
+		t.Error("RegisteredViews() does not include 'mock'")
+	}
+}
+
+func TestLookupViewUnknown(t *testing.T) {
+	RegisterView("mock", ViewFactoryFunc(func(opts ViewOptions) (View, error) {
+		return &MockView{}, nil
+	}))
+
+	_, err := LookupView("does-not-exist")
+	if !errors.Is(err, ErrViewNotRegistered) {
+		t.Fatalf("Expected ErrViewNotRegistered, got %v", err)
+	}
+	if !strings.Contains(err.Error(), "mock") {
+		t.Errorf("Expected error to list available views, got %q", err.Error())
+	}
+}
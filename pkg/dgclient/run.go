@@ -2,10 +2,12 @@ package dgclient
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"io"
 	"net"
 	"os"
+	"sort"
 	"strings"
 	"time"
 
@@ -26,7 +28,7 @@ func (c *Client) Run(ctx context.Context) error {
 	}
 
 	// Store auth method for reconnection
-	var lastAuth AuthMethod
+	lastAuth := c.auth
 	c.mu.Unlock()
 
 	// Main session loop with reconnection
@@ -52,14 +54,14 @@ func (c *Client) Run(ctx context.Context) error {
 		sshSession, err := sshClient.NewSession()
 		if err != nil {
 			// Try to reconnect if session creation fails
-			if reconnectErr := c.handleReconnection(lastAuth, err); reconnectErr != nil {
+			if reconnectErr := c.handleReconnection(ctx, lastAuth, err); reconnectErr != nil {
 				return fmt.Errorf("failed to create session and reconnect failed: %v (original: %v)", reconnectErr, err)
 			}
 			continue // Retry with new connection
 		}
 
 		c.mu.Lock()
-		c.session = NewSSHSession(sshSession)
+		c.session = NewSSHSessionWithModes(sshSession, c.config.terminalModes())
 		c.mu.Unlock()
 
 		// Run session
@@ -80,12 +82,12 @@ func (c *Client) Run(ctx context.Context) error {
 			}
 
 			// Check if this is a connection error that warrants reconnection
-			if c.shouldReconnect(sessionErr) {
+			if errors.Is(sessionErr, ErrReconnectRequested) || c.shouldReconnect(sessionErr) {
 				if c.config.Debug {
 					fmt.Printf("Session error occurred, attempting reconnection: %v\n", sessionErr)
 				}
 
-				if reconnectErr := c.handleReconnection(lastAuth, sessionErr); reconnectErr != nil {
+				if reconnectErr := c.handleReconnection(ctx, lastAuth, sessionErr); reconnectErr != nil {
 					return fmt.Errorf("session failed and reconnect failed: %v (original: %v)", reconnectErr, sessionErr)
 				}
 
@@ -105,7 +107,12 @@ func (c *Client) Run(ctx context.Context) error {
 
 // runSession handles a single session lifecycle
 func (c *Client) runSession(ctx context.Context) error {
-	// Set up PTY
+	c.resetBanner()
+
+	// Set up PTY, once the view knows its size
+	if err := c.waitSizeReady(ctx); err != nil {
+		return err
+	}
 	width, height := c.view.GetSize()
 	if err := c.session.RequestPTY(c.config.DefaultTerminal, height, width); err != nil {
 		return fmt.Errorf("failed to request PTY: %w", err)
@@ -122,34 +129,101 @@ func (c *Client) runSession(ctx context.Context) error {
 		return fmt.Errorf("failed to get stdout pipe: %w", err)
 	}
 
+	// Optionally log the raw byte stream for debugging
+	var rawLog *rawLogger
+	if c.config.RawLogPath != "" {
+		rawLog, err = openRawLog(c.config.RawLogPath)
+		if err != nil {
+			return fmt.Errorf("failed to open raw log: %w", err)
+		}
+		defer rawLog.Close()
+		stdout = rawLogReader{r: stdout, log: rawLog}
+	}
+
+	// Optionally coalesce bursts of input
+	var inputWriter io.Writer = stdin
+	if c.config.InputFlushInterval > 0 {
+		cw := newCoalescingWriter(stdin, c.config.InputFlushInterval, c.config.InputFlushThreshold)
+		defer cw.Close()
+		inputWriter = cw
+	}
+	writeInput := func(input []byte) error {
+		if rawLog != nil && c.config.RawLogInput {
+			rawLog.log(rawLogInput, input)
+		}
+		if _, err := inputWriter.Write(input); err != nil {
+			return err
+		}
+		c.inputStats.sent.Add(1)
+		return nil
+	}
+	c.mu.Lock()
+	c.sessionInput = writeInput
+	c.mu.Unlock()
+	defer func() {
+		c.mu.Lock()
+		c.sessionInput = nil
+		c.mu.Unlock()
+	}()
+
+	// Optionally watch for idle output to send refresh keys
+	var outputActivity chan struct{}
+	if c.config.IdleRefreshInterval > 0 && len(c.config.IdleRefreshKeys) > 0 {
+		outputActivity = make(chan struct{}, 1)
+		stdout = activityReader{r: stdout, activity: outputActivity}
+	}
+
+	c.requestEnv()
+
 	// Start shell
 	if err := c.session.Shell(); err != nil {
 		return fmt.Errorf("failed to start shell: %w", err)
 	}
 
 	// Create error channel for concurrent operations
-	errCh := make(chan error, 3)
+	errCh := make(chan error, 4)
 	sessionDone := make(chan struct{})
 
 	// Handle output
-	go func() {
-		defer close(sessionDone)
-		buf := make([]byte, 4096)
-		for {
-			n, err := stdout.Read(buf)
-			if err != nil {
-				if err != io.EOF {
-					errCh <- fmt.Errorf("stdout read error: %w", err)
+	if c.config.OutputBufferSize > 0 {
+		go c.pumpOutput(stdout, errCh, sessionDone)
+	} else {
+		go func() {
+			defer close(sessionDone)
+			buf := make([]byte, 4096)
+			for {
+				n, err := stdout.Read(buf)
+				if err != nil {
+					if err != io.EOF {
+						errCh <- fmt.Errorf("stdout read error: %w", err)
+					}
+					return
 				}
-				return
-			}
 
-			if err := c.view.Render(buf[:n]); err != nil {
-				errCh <- fmt.Errorf("render error: %w", err)
-				return
+				data := applyFilter(c.config.OutputFilter, buf[:n])
+				if len(data) == 0 {
+					continue
+				}
+				c.recordOutput(data)
+				if !c.trackBanner(data) {
+					continue
+				}
+				data = c.clearWelcome(data)
+
+				c.stats.recordRender(data)
+				if err := c.view.Render(data); err != nil {
+					errCh <- fmt.Errorf("render error: %w", err)
+					return
+				}
 			}
-		}
-	}()
+		}()
+	}
+
+	// Optionally debounce held-down keys
+	var debouncer *inputDebouncer
+	if c.config.RepeatWindow > 0 && len(c.config.RepeatKeys) > 0 {
+		debouncer = newInputDebouncer(c.config.RepeatWindow, c.config.RepeatKeys)
+	}
 
 	// Handle input
 	go func() {
@@ -170,13 +244,53 @@ func (c *Client) runSession(ctx context.Context) error {
 				return
 			}
 
-			if _, err := stdin.Write(input); err != nil {
+			c.inputStats.events.Add(1)
+			if debouncer != nil && !debouncer.allow(input) {
+				c.inputStats.debounced.Add(1)
+				continue
+			}
+			if seq, ok := c.config.KeySequences[string(input)]; ok {
+				input = seq
+			}
+
+			input = c.config.InputNormalization.apply(input)
+			input = applyFilter(c.config.InputFilter, input)
+			if len(input) == 0 {
+				c.inputStats.filtered.Add(1)
+				continue
+			}
+
+			if err := writeInput(input); err != nil {
 				errCh <- fmt.Errorf("stdin write error: %w", err)
 				return
 			}
 		}
 	}()
 
+	// Pass replies to terminal queries straight to the session
+	if rv, ok := c.view.(ResponseView); ok {
+		go func() {
+			responses := rv.Responses()
+			for {
+				select {
+				case <-sessionDone:
+					return
+				case <-ctx.Done():
+					return
+				case reply := <-responses:
+					if err := writeInput(reply); err != nil {
+						errCh <- fmt.Errorf("stdin write error: %w", err)
+						return
+					}
+				}
+			}
+		}()
+	}
+
+	if outputActivity != nil {
+		go c.idleRefresh(ctx, writeInput, outputActivity, sessionDone, errCh)
+	}
+
 	// Handle window resize
 	go func() {
 		// Monitor for resize events - this is a simplified version
@@ -198,15 +312,158 @@ func (c *Client) runSession(ctx context.Context) error {
 		}
 	}()
 
-	// Wait for completion or error
+	return c.waitSession(ctx, errCh, sessionDone)
+}
+
+// waitSession waits for the session to end or fail. The output loop reports
+// read and render errors before closing sessionDone, so those are preferred
+// over the exit status; a dropped connection then still reconnects.
+func (c *Client) waitSession(ctx context.Context, errCh <-chan error, sessionDone <-chan struct{}) error {
 	select {
 	case <-ctx.Done():
 		return ctx.Err()
+	case <-c.reconnectCh:
+		return ErrReconnectRequested
 	case err := <-errCh:
 		return err
 	case <-sessionDone:
+		select {
+		case err := <-errCh:
+			return err
+		default:
+		}
+		return c.waitExit(ctx)
+	}
+}
+
+// requestEnv sends the configured environment variables in name order.
+// Servers often refuse env requests, so failures are only reported in
+// debug mode.
+func (c *Client) requestEnv() {
+	session, ok := c.session.(EnvSession)
+	if !ok {
+		return
+	}
+
+	names := make([]string, 0, len(c.config.Env))
+	for name := range c.config.Env {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		if err := session.Setenv(name, c.config.Env[name]); err != nil && c.config.Debug {
+			fmt.Fprintf(os.Stderr, "Server rejected environment variable %s: %v\n", name, err)
+		}
+	}
+}
+
+// waitExit collects the remote exit status once session output has ended.
+// A non-zero status or a signal is returned as an *ExitError; a session
+// closed without any exit status is treated as a normal end.
+func (c *Client) waitExit(ctx context.Context) error {
+	waitErr := make(chan error, 1)
+	go func() { waitErr <- c.session.Wait() }()
+
+	var err error
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case err = <-waitErr:
+	}
+
+	var exitErr *ssh.ExitError
+	var missingErr *ssh.ExitMissingError
+	switch {
+	case err == nil, errors.As(err, &missingErr):
+		return nil
+	case errors.As(err, &exitErr):
+		return &ExitError{Status: exitErr.ExitStatus(), Signal: exitErr.Signal(), Err: err}
+	default:
+		return fmt.Errorf("session wait failed: %w", err)
+	}
+}
+
+// waitSizeReady waits for a SizeReadyView to report its size. On timeout
+// the session starts with the view's current size and is resized later.
+func (c *Client) waitSizeReady(ctx context.Context) error {
+	sv, ok := c.view.(SizeReadyView)
+	if !ok || c.config.SizeReadyTimeout <= 0 {
 		return nil
 	}
+
+	timer := time.NewTimer(c.config.SizeReadyTimeout)
+	defer timer.Stop()
+
+	select {
+	case <-sv.SizeReady():
+	case <-timer.C:
+		if c.config.Debug {
+			fmt.Fprintf(os.Stderr, "view size not ready after %v, using current size\n", c.config.SizeReadyTimeout)
+		}
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+	return nil
+}
+
+// pumpOutput reads session output into a bounded outputPump and renders it
+// from a separate goroutine, so a slow view coalesces output instead of
+// rendering stale chunks one by one. sessionDone is closed once all output
+// has been rendered.
+func (c *Client) pumpOutput(stdout io.Reader, errCh chan<- error, sessionDone chan<- struct{}) {
+	pump := newOutputPump(c.config.OutputBufferSize, &c.stats)
+
+	go func() {
+		defer pump.close()
+		buf := make([]byte, 4096)
+		for {
+			n, err := stdout.Read(buf)
+			if err != nil {
+				if err != io.EOF {
+					errCh <- fmt.Errorf("stdout read error: %w", err)
+				}
+				return
+			}
+
+			data := applyFilter(c.config.OutputFilter, buf[:n])
+			if len(data) == 0 {
+				continue
+			}
+			c.recordOutput(data)
+			if !c.trackBanner(data) {
+				continue
+			}
+			data = c.clearWelcome(data)
+
+			if !pump.push(data) {
+				return
+			}
+		}
+	}()
+
+	defer close(sessionDone)
+	for {
+		frame := pump.pop()
+		if frame == nil {
+			return
+		}
+
+		c.stats.recordRender(frame)
+		if err := c.view.Render(frame); err != nil {
+			pump.close()
+			errCh <- fmt.Errorf("render error: %w", err)
+			return
+		}
+	}
+}
+
+// applyFilter runs data through filter, treating a nil filter as passthrough
+func applyFilter(filter func([]byte) []byte, data []byte) []byte {
+	if filter == nil {
+		return data
+	}
+	return filter(data)
 }
 
 // shouldReconnect determines if an error warrants a reconnection attempt
@@ -238,8 +495,10 @@ func (c *Client) shouldReconnect(err error) bool {
 	return false
 }
 
-// handleReconnection manages the reconnection process
-func (c *Client) handleReconnection(lastAuth AuthMethod, originalErr error) error {
+// handleReconnection manages the reconnection process. Each attempt is
+// announced with a StateReconnecting event carrying the delay before it
+// starts; cancelling ctx during that delay abandons reconnection.
+func (c *Client) handleReconnection(ctx context.Context, lastAuth AuthMethod, originalErr error) error {
 	if c.config.MaxReconnectAttempts <= 0 {
 		return fmt.Errorf("reconnection disabled")
 	}
@@ -269,12 +528,30 @@ func (c *Client) handleReconnection(lastAuth AuthMethod, originalErr error) erro
 	// Attempt reconnection with exponential backoff
 	delay := c.config.ReconnectDelay
 	for i := 0; i < c.config.MaxReconnectAttempts; i++ {
+		var wait time.Duration
 		if i > 0 {
+			wait = delay
+			delay = time.Duration(float64(delay) * 1.5) // Exponential backoff
+		}
+
+		c.emitEvent(ConnectionEvent{
+			State:       StateReconnecting,
+			Attempt:     i + 1,
+			MaxAttempts: c.config.MaxReconnectAttempts,
+			Delay:       wait,
+			Err:         originalErr,
+		})
+
+		if wait > 0 {
 			if c.config.Debug {
-				fmt.Printf("Reconnection attempt %d/%d in %v...\n", i+1, c.config.MaxReconnectAttempts, delay)
+				fmt.Printf("Reconnection attempt %d/%d in %v...\n", i+1, c.config.MaxReconnectAttempts, wait)
+			}
+			select {
+			case <-ctx.Done():
+				c.emitEvent(ConnectionEvent{State: StateDisconnected, Err: ctx.Err()})
+				return ctx.Err()
+			case <-time.After(wait):
 			}
-			time.Sleep(delay)
-			delay = time.Duration(float64(delay) * 1.5) // Exponential backoff
 		}
 
 		err := c.Connect(host, port, lastAuth)
@@ -290,14 +567,29 @@ func (c *Client) handleReconnection(lastAuth AuthMethod, originalErr error) erro
 		}
 	}
 
-	return fmt.Errorf("failed to reconnect after %d attempts", c.config.MaxReconnectAttempts)
+	err := fmt.Errorf("failed to reconnect after %d attempts", c.config.MaxReconnectAttempts)
+	c.emitEvent(ConnectionEvent{State: StateDisconnected, Err: err})
+	return err
 }
 
 // ConnectWithConn establishes a connection to the dgamelaunch server using an existing net.Conn
 func (c *Client) ConnectWithConn(conn net.Conn, auth AuthMethod) error {
+	if err := c.connectWithConn(conn, auth); err != nil {
+		return err
+	}
+	if err := c.deliverAuthBanner(); err != nil {
+		return err
+	}
+	c.emitEvent(ConnectionEvent{State: StateConnected})
+	return nil
+}
+
+func (c *Client) connectWithConn(conn net.Conn, auth AuthMethod) error {
 	c.mu.Lock()
 	defer c.mu.Unlock()
 
+	c.games = nil
+	c.authBanner = ""
 	if c.connected {
 		// Allow reconnection by first disconnecting
 		if c.sshClient != nil {
@@ -317,11 +609,12 @@ func (c *Client) ConnectWithConn(conn net.Conn, auth AuthMethod) error {
 		User:            c.config.SSHConfig.User,
 		Auth:            []ssh.AuthMethod{sshAuth},
 		HostKeyCallback: c.config.SSHConfig.HostKeyCallback,
-		Timeout:         c.config.ConnectTimeout,
+		BannerCallback:  c.bannerCallback,
+		Timeout:         c.config.handshakeTimeout(),
 	}
 
 	// Perform SSH handshake on existing connection
-	sshConn, chans, reqs, err := ssh.NewClientConn(conn, conn.RemoteAddr().String(), config)
+	sshConn, chans, reqs, err := handshake(conn, conn.RemoteAddr().String(), config)
 	if err != nil {
 		conn.Close()
 		return &ConnectionError{Host: conn.RemoteAddr().String(), Port: 0, Err: err}
@@ -337,6 +630,8 @@ func (c *Client) ConnectWithConn(conn net.Conn, auth AuthMethod) error {
 		c.host = conn.RemoteAddr().String()
 		c.port = 0
 	}
+	c.auth = auth
+	c.latencySamples = nil
 	c.connected = true
 
 	// Start keepalive routine
@@ -347,9 +642,22 @@ func (c *Client) ConnectWithConn(conn net.Conn, auth AuthMethod) error {
 
 // Connect establishes a connection to the dgamelaunch server
 func (c *Client) Connect(host string, port int, auth AuthMethod) error {
+	if err := c.connect(host, port, auth); err != nil {
+		return err
+	}
+	if err := c.deliverAuthBanner(); err != nil {
+		return err
+	}
+	c.emitEvent(ConnectionEvent{State: StateConnected})
+	return nil
+}
+
+func (c *Client) connect(host string, port int, auth AuthMethod) error {
 	c.mu.Lock()
 	defer c.mu.Unlock()
 
+	c.games = nil
+	c.authBanner = ""
 	if c.connected {
 		// Allow reconnection by first disconnecting
 		if c.sshClient != nil {
@@ -369,18 +677,23 @@ func (c *Client) Connect(host string, port int, auth AuthMethod) error {
 		User:            c.config.SSHConfig.User,
 		Auth:            []ssh.AuthMethod{sshAuth},
 		HostKeyCallback: c.config.SSHConfig.HostKeyCallback,
-		Timeout:         c.config.ConnectTimeout,
+		BannerCallback:  c.bannerCallback,
+		Timeout:         c.config.handshakeTimeout(),
 	}
 
 	// Connect with timeout
+	dial := c.config.Dial
+	if dial == nil {
+		dial = net.DialTimeout
+	}
 	address := net.JoinHostPort(host, fmt.Sprintf("%d", port))
-	conn, err := net.DialTimeout("tcp", address, c.config.ConnectTimeout)
+	conn, err := dial("tcp", address, c.config.dialTimeout())
 	if err != nil {
 		return &ConnectionError{Host: host, Port: port, Err: err}
 	}
 
 	// Perform SSH handshake
-	sshConn, chans, reqs, err := ssh.NewClientConn(conn, address, config)
+	sshConn, chans, reqs, err := handshake(conn, address, config)
 	if err != nil {
 		conn.Close()
 		return &ConnectionError{Host: host, Port: port, Err: err}
@@ -389,6 +702,8 @@ func (c *Client) Connect(host string, port int, auth AuthMethod) error {
 	c.sshClient = ssh.NewClient(sshConn, chans, reqs)
 	c.host = host
 	c.port = port
+	c.auth = auth
+	c.latencySamples = nil
 	c.connected = true
 
 	// Start keepalive routine
@@ -396,3 +711,27 @@ func (c *Client) Connect(host string, port int, auth AuthMethod) error {
 
 	return nil
 }
+
+// handshake performs the SSH handshake on conn, bounded by config.Timeout.
+// ssh.NewClientConn itself applies no timeout, so a deadline is set on the
+// connection for the duration of the handshake.
+func handshake(conn net.Conn, address string, config *ssh.ClientConfig) (ssh.Conn, <-chan ssh.NewChannel, <-chan *ssh.Request, error) {
+	if config.Timeout > 0 {
+		if err := conn.SetDeadline(time.Now().Add(config.Timeout)); err != nil {
+			return nil, nil, nil, fmt.Errorf("failed to set handshake deadline: %w", err)
+		}
+	}
+
+	sshConn, chans, reqs, err := ssh.NewClientConn(conn, address, config)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	if config.Timeout > 0 {
+		if err := conn.SetDeadline(time.Time{}); err != nil {
+			sshConn.Close()
+			return nil, nil, nil, fmt.Errorf("failed to clear handshake deadline: %w", err)
+		}
+	}
+	return sshConn, chans, reqs, nil
+}
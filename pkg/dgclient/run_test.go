@@ -0,0 +1,481 @@
+package dgclient
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"golang.org/x/crypto/ssh"
+)
+
+// fakeSession implements Session over in-memory pipes for testing runSession
+type fakeSession struct {
+	mu     sync.Mutex
+	stdin  bytes.Buffer
+	stdout io.Reader
+
+	ptyTerm          string
+	ptyW, ptyH       int
+	windowChanges    int
+	stdinClosed      bool
+	shellStarted     bool
+	requestPTYCalled bool
+	waitErr          error
+
+	env       map[string]string
+	envOrder  []string
+	rejectEnv map[string]bool
+}
+
+type fakeStdin struct{ s *fakeSession }
+
+func (f fakeStdin) Write(p []byte) (int, error) {
+	f.s.mu.Lock()
+	defer f.s.mu.Unlock()
+	return f.s.stdin.Write(p)
+}
+
+func (f fakeStdin) Close() error {
+	f.s.mu.Lock()
+	defer f.s.mu.Unlock()
+	f.s.stdinClosed = true
+	return nil
+}
+
+func (s *fakeSession) RequestPTY(term string, h, w int) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.requestPTYCalled = true
+	s.ptyTerm, s.ptyH, s.ptyW = term, h, w
+	return nil
+}
+
+func (s *fakeSession) WindowChange(h, w int) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.windowChanges++
+	return nil
+}
+
+func (s *fakeSession) Setenv(name, value string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.envOrder = append(s.envOrder, name)
+	if s.rejectEnv[name] {
+		return errors.New("env request denied")
+	}
+	if s.env == nil {
+		s.env = make(map[string]string)
+	}
+	s.env[name] = value
+	return nil
+}
+
+func (s *fakeSession) StdinPipe() (io.WriteCloser, error) { return fakeStdin{s}, nil }
+func (s *fakeSession) StdoutPipe() (io.Reader, error)     { return s.stdout, nil }
+func (s *fakeSession) StderrPipe() (io.Reader, error)     { return bytes.NewReader(nil), nil }
+func (s *fakeSession) Start(cmd string) error             { return nil }
+func (s *fakeSession) Wait() error                        { return s.waitErr }
+func (s *fakeSession) Signal(sig ssh.Signal) error        { return nil }
+func (s *fakeSession) Close() error                       { return nil }
+
+func (s *fakeSession) Shell() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.shellStarted = true
+	return nil
+}
+
+func (s *fakeSession) Stdin() string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.stdin.String()
+}
+
+// scriptedView is a View that replays queued input and records rendered output
+type scriptedView struct {
+	MockView
+
+	mu        sync.Mutex
+	rendered  bytes.Buffer
+	inputCh   chan []byte
+	responses chan []byte
+}
+
+func newScriptedView(inputs ...string) *scriptedView {
+	v := &scriptedView{inputCh: make(chan []byte, len(inputs))}
+	for _, in := range inputs {
+		v.inputCh <- []byte(in)
+	}
+	return v
+}
+
+func (v *scriptedView) Render(data []byte) error {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	v.rendered.Write(data)
+	return nil
+}
+
+func (v *scriptedView) HandleInput() ([]byte, error) {
+	in, ok := <-v.inputCh
+	if !ok {
+		return nil, io.EOF
+	}
+	return in, nil
+}
+
+func (v *scriptedView) Responses() <-chan []byte { return v.responses }
+
+func (v *scriptedView) Rendered() string {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	return v.rendered.String()
+}
+
+// runFakeSession runs runSession against the given fakes, feeding output
+// after all queued input has been consumed, and waits for completion.
+func runFakeSession(t *testing.T, config *ClientConfig, view *scriptedView, output string) *fakeSession {
+	t.Helper()
+
+	client := NewClient(config)
+	defer client.Close()
+	return runFakeClient(t, client, view, output)
+}
+
+// runFakeClient is runFakeSession for tests that inspect the client afterwards
+func runFakeClient(t *testing.T, client *Client, view *scriptedView, output string) *fakeSession {
+	t.Helper()
+
+	pr, pw := io.Pipe()
+	session := &fakeSession{stdout: pr}
+	client.view = view
+	client.session = session
+
+	done := make(chan error, 1)
+	go func() { done <- client.runSession(context.Background()) }()
+
+	// Wait for queued input to drain before producing output
+	deadline := time.Now().Add(time.Second)
+	for len(view.inputCh) > 0 && time.Now().Before(deadline) {
+		time.Sleep(5 * time.Millisecond)
+	}
+	time.Sleep(20 * time.Millisecond)
+
+	pw.Write([]byte(output))
+	pw.Close()
+
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatalf("runSession() failed: %v", err)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("runSession() did not finish")
+	}
+
+	return session
+}
+
+func TestRunSessionInputFilterUppercase(t *testing.T) {
+	config := DefaultClientConfig()
+	config.InputFilter = bytes.ToUpper
+
+	view := newScriptedView("abc", "d")
+	session := runFakeSession(t, config, view, "ok")
+
+	if got := session.Stdin(); got != "ABCD" {
+		t.Errorf("Expected filtered input 'ABCD', got %q", got)
+	}
+}
+
+func TestRunSessionOutputFilterDropsByte(t *testing.T) {
+	config := DefaultClientConfig()
+	config.OutputFilter = func(data []byte) []byte {
+		return bytes.ReplaceAll(data, []byte{0x07}, nil)
+	}
+
+	view := newScriptedView()
+	runFakeSession(t, config, view, "ding\x07dong")
+
+	if got := view.Rendered(); got != "dingdong" {
+		t.Errorf("Expected filtered output 'dingdong', got %q", got)
+	}
+}
+
+// sizeReadyView reports its real size only after ready is closed
+type sizeReadyView struct {
+	*scriptedView
+
+	mu            sync.Mutex
+	width, height int
+	ready         chan struct{}
+}
+
+func (v *sizeReadyView) GetSize() (int, int) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	return v.width, v.height
+}
+
+func (v *sizeReadyView) SizeReady() <-chan struct{} { return v.ready }
+
+func TestRunSessionWaitsForSizeReady(t *testing.T) {
+	view := &sizeReadyView{scriptedView: newScriptedView(), width: 80, height: 24, ready: make(chan struct{})}
+	close(view.inputCh)
+
+	go func() {
+		time.Sleep(20 * time.Millisecond)
+		view.mu.Lock()
+		view.width, view.height = 132, 43
+		view.mu.Unlock()
+		close(view.ready)
+	}()
+
+	session := &fakeSession{stdout: bytes.NewReader(nil)}
+	client := NewClient(nil)
+	defer client.Close()
+	client.view = view
+	client.session = session
+
+	if err := client.runSession(context.Background()); err != nil {
+		t.Fatalf("runSession() failed: %v", err)
+	}
+
+	if session.ptyW != 132 || session.ptyH != 43 {
+		t.Errorf("Expected PTY 132x43, got %dx%d", session.ptyW, session.ptyH)
+	}
+}
+
+func TestRunSessionSizeReadyTimeout(t *testing.T) {
+	view := &sizeReadyView{scriptedView: newScriptedView(), width: 100, height: 30, ready: make(chan struct{})}
+	close(view.inputCh)
+
+	config := DefaultClientConfig()
+	config.SizeReadyTimeout = 10 * time.Millisecond
+	session := &fakeSession{stdout: bytes.NewReader(nil)}
+	client := NewClient(config)
+	defer client.Close()
+	client.view = view
+	client.session = session
+
+	if err := client.runSession(context.Background()); err != nil {
+		t.Fatalf("runSession() failed: %v", err)
+	}
+
+	if !session.requestPTYCalled || session.ptyW != 100 || session.ptyH != 30 {
+		t.Errorf("Expected PTY at current size 100x30, got %dx%d", session.ptyW, session.ptyH)
+	}
+}
+
+func TestRunSessionExitStatus(t *testing.T) {
+	boom := errors.New("connection lost")
+	tests := []struct {
+		name     string
+		waitErr  error
+		wantExit bool
+		wantErr  bool
+	}{
+		{"clean exit", nil, false, false},
+		{"exit status missing", &ssh.ExitMissingError{}, false, false},
+		{"remote exit status", &ssh.ExitError{}, true, true},
+		{"wait failure", boom, false, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			view := newScriptedView()
+			close(view.inputCh)
+
+			client := NewClient(nil)
+			defer client.Close()
+			client.view = view
+			client.session = &fakeSession{stdout: bytes.NewReader([]byte("bye")), waitErr: tt.waitErr}
+
+			err := client.runSession(context.Background())
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Expected error %v, got %v", tt.wantErr, err)
+			}
+
+			var exitErr *ExitError
+			if errors.As(err, &exitErr) != tt.wantExit {
+				t.Errorf("Expected ExitError %v, got %v", tt.wantExit, err)
+			}
+			if tt.waitErr == boom && !errors.Is(err, boom) {
+				t.Errorf("Expected wait error to be wrapped, got %v", err)
+			}
+		})
+	}
+}
+
+func TestWaitSessionPrefersOutputError(t *testing.T) {
+	boom := errors.New("connection reset")
+	client := NewClient(nil)
+	defer client.Close()
+	client.session = &fakeSession{waitErr: &ssh.ExitMissingError{}}
+
+	// A failed read both reports its error and ends the session; repeat so
+	// select sees the two cases ready in either order
+	for i := 0; i < 50; i++ {
+		errCh := make(chan error, 1)
+		errCh <- boom
+		sessionDone := make(chan struct{})
+		close(sessionDone)
+
+		if err := client.waitSession(context.Background(), errCh, sessionDone); !errors.Is(err, boom) {
+			t.Fatalf("Expected read error, got %v", err)
+		}
+	}
+}
+
+func TestExitErrorMessage(t *testing.T) {
+	if got := (&ExitError{Status: 3}).Error(); got != "remote session exited with status 3" {
+		t.Errorf("Expected status message, got %q", got)
+	}
+	if got := (&ExitError{Signal: "KILL"}).Error(); got != "remote session killed by signal KILL" {
+		t.Errorf("Expected signal message, got %q", got)
+	}
+}
+
+func TestRunSessionIdleRefresh(t *testing.T) {
+	config := DefaultClientConfig()
+	config.IdleRefreshInterval = 50 * time.Millisecond
+	config.IdleRefreshKeys = []byte("\x12")
+
+	pr, pw := io.Pipe()
+	session := &fakeSession{stdout: pr}
+	client := NewClient(config)
+	defer client.Close()
+	client.view = newScriptedView()
+	client.session = session
+
+	done := make(chan error, 1)
+	go func() { done <- client.runSession(context.Background()) }()
+
+	// Steady output keeps the session from counting as idle
+	for i := 0; i < 10; i++ {
+		pw.Write([]byte("."))
+		time.Sleep(10 * time.Millisecond)
+	}
+	if got := session.Stdin(); got != "" {
+		t.Errorf("Expected no refresh while output arrives, got %q", got)
+	}
+
+	// Then wait for at least one refresh once output stops
+	deadline := time.Now().Add(time.Second)
+	for session.Stdin() == "" && time.Now().Before(deadline) {
+		time.Sleep(5 * time.Millisecond)
+	}
+	if got := session.Stdin(); got == "" || strings.Trim(got, "\x12") != "" {
+		t.Errorf("Expected only refresh keys after idle, got %q", got)
+	}
+
+	pw.Close()
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatalf("runSession() failed: %v", err)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("runSession() did not finish")
+	}
+}
+
+func TestRunSessionKeySequences(t *testing.T) {
+	config := DefaultClientConfig()
+	config.KeySequences = map[string][]byte{
+		"R":      []byte("Gj"),
+		"\x1b[A": []byte("k"),
+	}
+
+	view := newScriptedView("R", "\x1b[A", "a")
+	session := runFakeSession(t, config, view, "ok")
+
+	if got := session.Stdin(); got != "Gjka" {
+		t.Errorf("Expected expanded input 'Gjka', got %q", got)
+	}
+}
+
+func TestRunSessionRequestsEnv(t *testing.T) {
+	config := DefaultClientConfig()
+	config.Env = map[string]string{
+		"NETHACKOPTIONS": "autopickup,pickup_types:$",
+		"LANG":           "en_US.UTF-8",
+		"SECRET":         "x",
+	}
+
+	pr, pw := io.Pipe()
+	session := &fakeSession{stdout: pr, rejectEnv: map[string]bool{"SECRET": true}}
+	client := NewClient(config)
+	defer client.Close()
+	client.view = newScriptedView()
+	client.session = session
+
+	done := make(chan error, 1)
+	go func() { done <- client.runSession(context.Background()) }()
+	pw.Close()
+	if err := <-done; err != nil {
+		t.Fatalf("Expected rejected env request to be non-fatal, got %v", err)
+	}
+
+	if got := strings.Join(session.envOrder, ","); got != "LANG,NETHACKOPTIONS,SECRET" {
+		t.Errorf("Expected env requested in name order, got %s", got)
+	}
+	if session.env["NETHACKOPTIONS"] != "autopickup,pickup_types:$" || session.env["LANG"] != "en_US.UTF-8" {
+		t.Errorf("Unexpected env: %v", session.env)
+	}
+	if !session.shellStarted {
+		t.Error("Expected shell to start after env requests")
+	}
+}
+
+func TestRunSessionWithoutEnvSupport(t *testing.T) {
+	config := DefaultClientConfig()
+	config.Env = map[string]string{"LANG": "en_US.UTF-8"}
+
+	pr, pw := io.Pipe()
+	session := &fakeSession{stdout: pr}
+	client := NewClient(config)
+	defer client.Close()
+	client.view = newScriptedView()
+	// Hide Setenv, as a Session implemented outside this package might
+	client.session = struct{ Session }{session}
+
+	done := make(chan error, 1)
+	go func() { done <- client.runSession(context.Background()) }()
+	pw.Close()
+	if err := <-done; err != nil {
+		t.Fatalf("runSession() failed: %v", err)
+	}
+	if !session.shellStarted || len(session.envOrder) != 0 {
+		t.Errorf("Expected shell started without env requests, got %v", session.envOrder)
+	}
+}
+
+func TestRunSessionWritesResponsesUnchanged(t *testing.T) {
+	config := DefaultClientConfig()
+	config.RepeatWindow = time.Hour
+	config.RepeatKeys = []string{"\x1b[8;24;80t"}
+	config.InputFilter = func([]byte) []byte { return nil }
+	view := newScriptedView()
+	close(view.inputCh)
+	view.responses = make(chan []byte, 2)
+	view.responses <- []byte("\x1b[8;24;80t")
+	view.responses <- []byte("\x1b[8;24;80t")
+
+	client := NewClient(config)
+	defer client.Close()
+	session := runFakeClient(t, client, view, "")
+
+	if got := session.Stdin(); got != "\x1b[8;24;80t\x1b[8;24;80t" {
+		t.Errorf("Expected both replies written unchanged, got %q", got)
+	}
+	if events := client.InputStats().Events; events != 0 {
+		t.Errorf("Expected replies not counted as input events, got %d", events)
+	}
+}
@@ -0,0 +1,223 @@
+package dgclient
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DefaultScriptStepTimeout is used for script steps that do not set a timeout
+const DefaultScriptStepTimeout = 10 * time.Second
+
+// maxExpectBuffer is the amount of unmatched output retained for Expect. The
+// buffer is trimmed back to this size once it holds twice as much, so the
+// copy is paid once per maxExpectBuffer bytes rather than on every read.
+const maxExpectBuffer = 64 * 1024
+
+// ScriptStep is a single expect/send pair of a login script.
+//
+// Expect is a regular expression matched against session output received
+// since the previous match; an empty Expect sends immediately. Send is written
+// to the session once Expect matches and supports the escapes \r, \n, \t,
+// \e (ESC), \\ and \xHH, so "\x1b" or "\e" both send an escape key.
+type ScriptStep struct {
+	Expect  string        `yaml:"expect,omitempty"`
+	Send    string        `yaml:"send,omitempty"`
+	Timeout time.Duration `yaml:"timeout,omitempty"`
+}
+
+// RunScript runs the given steps in order against the active session.
+// It is meant to run alongside Run, which feeds session output to Expect.
+// On failure the returned *ScriptError identifies the failing step.
+func (c *Client) RunScript(ctx context.Context, steps []ScriptStep) error {
+	for i, step := range steps {
+		if err := c.runScriptStep(ctx, step); err != nil {
+			return &ScriptError{Step: i + 1, Expect: step.Expect, Err: err}
+		}
+	}
+	return nil
+}
+
+func (c *Client) runScriptStep(ctx context.Context, step ScriptStep) error {
+	keys, err := DecodeScriptKeys(step.Send)
+	if err != nil {
+		return err
+	}
+
+	if step.Expect != "" {
+		re, err := regexp.Compile(step.Expect)
+		if err != nil {
+			return fmt.Errorf("invalid expect pattern: %w", err)
+		}
+
+		timeout := step.Timeout
+		if timeout <= 0 {
+			timeout = DefaultScriptStepTimeout
+		}
+
+		if _, err := c.Expect(ctx, re, timeout); err != nil {
+			return err
+		}
+	}
+
+	if len(keys) == 0 {
+		return nil
+	}
+	return c.Send(keys)
+}
+
+// Send writes bytes to the active session after the InputFilter. While Run
+// is driving the session, they take the same path as typed input, so they
+// stay in order with it and reach the raw log.
+func (c *Client) Send(data []byte) error {
+	c.mu.RLock()
+	session := c.session
+	write := c.sessionInput
+	c.mu.RUnlock()
+
+	if session == nil {
+		return ErrSessionNotStarted
+	}
+
+	data = applyFilter(c.config.InputFilter, data)
+	if len(data) == 0 {
+		return nil
+	}
+	if write != nil {
+		if err := write(data); err != nil {
+			return fmt.Errorf("failed to send input: %w", err)
+		}
+		return nil
+	}
+
+	// No session loop yet, as when a login script runs before Run
+	stdin, err := session.StdinPipe()
+	if err != nil {
+		return fmt.Errorf("failed to get stdin pipe: %w", err)
+	}
+
+	if _, err := stdin.Write(data); err != nil {
+		return fmt.Errorf("failed to send input: %w", err)
+	}
+	c.inputStats.sent.Add(1)
+	return nil
+}
+
+// Expect waits until session output matches re, or the timeout elapses.
+// Output up to the end of the match is consumed, so consecutive calls match
+// successive parts of the stream. It returns the matched text.
+func (c *Client) Expect(ctx context.Context, re *regexp.Regexp, timeout time.Duration) (string, error) {
+	var match string
+	err := c.waitOutput(ctx, timeout, func(buf []byte) (int, bool) {
+		loc := re.FindIndex(buf)
+		if loc == nil {
+			return 0, false
+		}
+		match = string(buf[loc[0]:loc[1]])
+		return loc[1], true
+	})
+	if errors.Is(err, ErrExpectTimeout) {
+		return "", fmt.Errorf("%w after %v waiting for %q", ErrExpectTimeout, timeout, re.String())
+	}
+	return match, err
+}
+
+// waitOutput calls match with the unconsumed session output each time new
+// output arrives, until it reports ok or the timeout elapses. On success the
+// first end bytes of output are consumed.
+func (c *Client) waitOutput(ctx context.Context, timeout time.Duration, match func(buf []byte) (end int, ok bool)) error {
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+
+	for {
+		c.outputMu.Lock()
+		if end, ok := match(c.outputBuf); ok {
+			c.outputBuf = append(c.outputBuf[:0], c.outputBuf[end:]...)
+			c.outputMu.Unlock()
+			return nil
+		}
+		if c.outputNotify == nil {
+			c.outputNotify = make(chan struct{})
+		}
+		notify := c.outputNotify
+		c.outputMu.Unlock()
+
+		select {
+		case <-notify:
+		case <-timer.C:
+			return ErrExpectTimeout
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-c.done:
+			return fmt.Errorf("client closed")
+		}
+	}
+}
+
+// recordOutput appends session output for Expect and wakes any waiters
+func (c *Client) recordOutput(data []byte) {
+	c.outputMu.Lock()
+	defer c.outputMu.Unlock()
+
+	c.outputBuf = append(c.outputBuf, data...)
+	if len(c.outputBuf) > 2*maxExpectBuffer {
+		c.outputBuf = append(c.outputBuf[:0], c.outputBuf[len(c.outputBuf)-maxExpectBuffer:]...)
+	}
+
+	// The channel is only created by a waiting waitOutput
+	if c.outputNotify != nil {
+		close(c.outputNotify)
+		c.outputNotify = nil
+	}
+}
+
+// DecodeScriptKeys expands the escape sequences supported in ScriptStep.Send
+func DecodeScriptKeys(s string) ([]byte, error) {
+	if !strings.Contains(s, `\`) {
+		return []byte(s), nil
+	}
+
+	out := make([]byte, 0, len(s))
+	for i := 0; i < len(s); i++ {
+		if s[i] != '\\' {
+			out = append(out, s[i])
+			continue
+		}
+
+		if i+1 >= len(s) {
+			return nil, fmt.Errorf("trailing backslash in %q", s)
+		}
+		i++
+
+		switch s[i] {
+		case 'r':
+			out = append(out, '\r')
+		case 'n':
+			out = append(out, '\n')
+		case 't':
+			out = append(out, '\t')
+		case 'e':
+			out = append(out, 0x1b)
+		case '\\':
+			out = append(out, '\\')
+		case 'x':
+			if i+2 >= len(s) {
+				return nil, fmt.Errorf("incomplete \\x escape in %q", s)
+			}
+			v, err := strconv.ParseUint(s[i+1:i+3], 16, 8)
+			if err != nil {
+				return nil, fmt.Errorf("invalid \\x escape in %q: %w", s, err)
+			}
+			out = append(out, byte(v))
+			i += 2
+		default:
+			return nil, fmt.Errorf("unknown escape \\%c in %q", s[i], s)
+		}
+	}
+
+	return out, nil
+}
@@ -0,0 +1,170 @@
+package dgclient
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestDecodeScriptKeys(t *testing.T) {
+	tests := []struct {
+		input   string
+		want    string
+		wantErr bool
+	}{
+		{"player1", "player1", false},
+		{`secret\r`, "secret\r", false},
+		{`\x1bq`, "\x1bq", false},
+		{`\e\n\t\\`, "\x1b\n\t\\", false},
+		{`bad\`, "", true},
+		{`\xZZ`, "", true},
+		{`\q`, "", true},
+	}
+
+	for _, tt := range tests {
+		got, err := DecodeScriptKeys(tt.input)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("DecodeScriptKeys(%q) error = %v, wantErr %v", tt.input, err, tt.wantErr)
+			continue
+		}
+		if !tt.wantErr && string(got) != tt.want {
+			t.Errorf("DecodeScriptKeys(%q) = %q, want %q", tt.input, got, tt.want)
+		}
+	}
+}
+
+func TestRunScript(t *testing.T) {
+	client := NewClient(nil)
+	defer client.Close()
+	session := &fakeSession{}
+	client.session = session
+
+	go func() {
+		time.Sleep(10 * time.Millisecond)
+		client.recordOutput([]byte("Welcome!\r\nlogin: "))
+		time.Sleep(10 * time.Millisecond)
+		client.recordOutput([]byte("password: "))
+	}()
+
+	steps := []ScriptStep{
+		{Expect: "login:", Send: `player1\r`},
+		{Expect: "password:", Send: `hunter2\r`},
+	}
+
+	if err := client.RunScript(context.Background(), steps); err != nil {
+		t.Fatalf("RunScript() failed: %v", err)
+	}
+
+	if got := session.Stdin(); got != "player1\rhunter2\r" {
+		t.Errorf("Expected script input %q, got %q", "player1\rhunter2\r", got)
+	}
+	if sent := client.InputStats().Sent; sent != 2 {
+		t.Errorf("Expected script sends counted in InputStats, got %d", sent)
+	}
+}
+
+func TestRunScriptTimeout(t *testing.T) {
+	client := NewClient(nil)
+	defer client.Close()
+	client.session = &fakeSession{}
+	client.recordOutput([]byte("login: "))
+
+	steps := []ScriptStep{
+		{Expect: "login:", Send: "player1"},
+		{Expect: "password:", Send: "secret", Timeout: 20 * time.Millisecond},
+	}
+
+	err := client.RunScript(context.Background(), steps)
+
+	var scriptErr *ScriptError
+	if !errors.As(err, &scriptErr) {
+		t.Fatalf("Expected ScriptError, got %v", err)
+	}
+	if scriptErr.Step != 2 {
+		t.Errorf("Expected failure at step 2, got step %d", scriptErr.Step)
+	}
+	if !errors.Is(err, ErrExpectTimeout) {
+		t.Errorf("Expected ErrExpectTimeout, got %v", err)
+	}
+}
+
+func TestRecordOutputKeepsRecentOutput(t *testing.T) {
+	client := NewClient(nil)
+	defer client.Close()
+
+	chunk := bytes.Repeat([]byte("."), 1024)
+	for i := 0; i < 3*maxExpectBuffer/len(chunk); i++ {
+		client.recordOutput(chunk)
+	}
+	client.recordOutput([]byte("login: "))
+
+	if n := len(client.outputBuf); n < maxExpectBuffer || n > 2*maxExpectBuffer {
+		t.Errorf("Expected between %d and %d buffered bytes, got %d", maxExpectBuffer, 2*maxExpectBuffer, n)
+	}
+	if client.outputNotify != nil {
+		t.Error("Expected no notify channel without waiters")
+	}
+
+	re := regexp.MustCompile(`login: $`)
+	if _, err := client.Expect(context.Background(), re, 10*time.Millisecond); err != nil {
+		t.Errorf("Expected the latest output to match, got %v", err)
+	}
+}
+
+func TestSendUsesSessionInputPath(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "raw.log")
+	config := DefaultClientConfig()
+	config.RawLogPath = path
+	config.RawLogInput = true
+	config.InputFilter = bytes.ToUpper
+	view := newScriptedView()
+	close(view.inputCh)
+
+	pr, pw := io.Pipe()
+	session := &fakeSession{stdout: pr}
+	client := NewClient(config)
+	defer client.Close()
+	client.view = view
+	client.session = session
+
+	done := make(chan error, 1)
+	go func() { done <- client.runSession(context.Background()) }()
+
+	deadline := time.Now().Add(time.Second)
+	for {
+		client.mu.RLock()
+		running := client.sessionInput != nil
+		client.mu.RUnlock()
+		if running {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("Expected the session input path to be set up")
+		}
+		time.Sleep(time.Millisecond)
+	}
+	if err := client.Send([]byte("play\r")); err != nil {
+		t.Fatalf("Send() failed: %v", err)
+	}
+	pw.Close()
+	if err := <-done; err != nil {
+		t.Fatalf("runSession() failed: %v", err)
+	}
+
+	if got := session.Stdin(); got != "PLAY\r" {
+		t.Errorf("Expected filtered input %q, got %q", "PLAY\r", got)
+	}
+	if data, _ := os.ReadFile(path); !strings.Contains(string(data), ` > "PLAY\r"`) {
+		t.Errorf("Expected sent bytes in raw log, got %q", data)
+	}
+	if sent := client.InputStats().Sent; sent != 1 {
+		t.Errorf("Expected one counted write, got %d", sent)
+	}
+}
@@ -41,13 +41,25 @@ type Session interface {
 	Close() error
 }
 
+// EnvSession is implemented by sessions that can pass environment
+// variables to the remote command. ClientConfig.Env is ignored for sessions
+// without it.
+type EnvSession interface {
+	Session
+
+	// Setenv requests an environment variable for the remote command.
+	// Servers commonly restrict which names they accept.
+	Setenv(name, value string) error
+}
+
 // sshSession implements Session using golang.org/x/crypto/ssh
 type sshSession struct {
 	session *ssh.Session
 	stdin   io.WriteCloser
-	stdout  io.Reader
+	stdout  *stdoutReader
 	stderr  io.Reader
 
+	modes      ssh.TerminalModes
 	mu         sync.Mutex
 	started    bool
 	ptyRequest *ptyRequestInfo
@@ -59,10 +71,26 @@ type ptyRequestInfo struct {
 	width  int
 }
 
+// DefaultTerminalModes returns the terminal modes sent with a PTY request
+func DefaultTerminalModes() ssh.TerminalModes {
+	return ssh.TerminalModes{
+		ssh.ECHO:          1,     // enable echoing
+		ssh.TTY_OP_ISPEED: 14400, // input speed = 14.4kbaud
+		ssh.TTY_OP_OSPEED: 14400, // output speed = 14.4kbaud
+	}
+}
+
 // NewSSHSession creates a new Session from an ssh.Session
 func NewSSHSession(session *ssh.Session) Session {
+	return NewSSHSessionWithModes(session, DefaultTerminalModes())
+}
+
+// NewSSHSessionWithModes creates a new Session that requests its PTY with
+// the given terminal modes
+func NewSSHSessionWithModes(session *ssh.Session, modes ssh.TerminalModes) Session {
 	return &sshSession{
 		session: session,
+		modes:   modes,
 	}
 }
 
@@ -74,14 +102,7 @@ func (s *sshSession) RequestPTY(term string, h, w int) error {
 		return fmt.Errorf("cannot request PTY after session started")
 	}
 
-	// SSH PTY request includes terminal modes
-	modes := ssh.TerminalModes{
-		ssh.ECHO:          1,     // enable echoing
-		ssh.TTY_OP_ISPEED: 14400, // input speed = 14.4kbaud
-		ssh.TTY_OP_OSPEED: 14400, // output speed = 14.4kbaud
-	}
-
-	if err := s.session.RequestPty(term, h, w, modes); err != nil {
+	if err := s.session.RequestPty(term, h, w, s.modes); err != nil {
 		return fmt.Errorf("PTY request failed: %w", err)
 	}
 
@@ -112,6 +133,20 @@ func (s *sshSession) WindowChange(h, w int) error {
 	return nil
 }
 
+func (s *sshSession) Setenv(name, value string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.started {
+		return fmt.Errorf("cannot set environment after session started")
+	}
+
+	if err := s.session.Setenv(name, value); err != nil {
+		return fmt.Errorf("env request for %s failed: %w", name, err)
+	}
+	return nil
+}
+
 func (s *sshSession) StdinPipe() (io.WriteCloser, error) {
 	s.mu.Lock()
 	defer s.mu.Unlock()
@@ -142,8 +177,8 @@ func (s *sshSession) StdoutPipe() (io.Reader, error) {
 		return nil, fmt.Errorf("failed to create stdout pipe: %w", err)
 	}
 
-	s.stdout = stdout
-	return stdout, nil
+	s.stdout = newStdoutReader(stdout)
+	return s.stdout, nil
 }
 
 func (s *sshSession) StderrPipe() (io.Reader, error) {
@@ -216,6 +251,9 @@ func (s *sshSession) Close() error {
 	if s.stdin != nil {
 		s.stdin.Close()
 	}
+	if s.stdout != nil {
+		s.stdout.Close()
+	}
 
 	if err := s.session.Close(); err != nil {
 		return fmt.Errorf("failed to close session: %w", err)
@@ -0,0 +1,119 @@
+package dgclient
+
+import (
+	"io"
+	"sync"
+	"time"
+)
+
+// stdoutReader wraps session output so a caller can stop waiting for it, as
+// ListGames does once the menu goes idle, without losing data. Such waits
+// read on a background goroutine; a read still in flight when the caller
+// gives up is collected by the next reader. With no timed read pending, Read
+// goes straight to the underlying reader, so the session's output loop pays
+// no goroutine handoff or allocation.
+type stdoutReader struct {
+	r    io.Reader
+	done chan struct{}
+	once sync.Once
+
+	mu       sync.Mutex
+	buf      []byte           // reused by background reads
+	inflight chan stdoutChunk // result of the background read, if one is running
+	pending  []byte
+	err      error
+}
+
+type stdoutChunk struct {
+	data []byte
+	err  error
+}
+
+func newStdoutReader(r io.Reader) *stdoutReader {
+	return &stdoutReader{r: r, done: make(chan struct{})}
+}
+
+// Read implements io.Reader
+func (s *stdoutReader) Read(p []byte) (int, error) {
+	s.mu.Lock()
+	direct := len(s.pending) == 0 && s.err == nil && s.inflight == nil
+	s.mu.Unlock()
+	if direct {
+		return s.r.Read(p)
+	}
+
+	data, _, err := s.next(nil, nil)
+	n := copy(p, data)
+	s.unread(data[n:])
+	return n, err
+}
+
+// next returns pending data or the next chunk, waiting until one arrives,
+// stop is closed or timeout fires. ok is false if it gave up waiting. A
+// read error is returned once the data before it has been taken. The
+// returned data is only valid until the next call.
+func (s *stdoutReader) next(stop <-chan struct{}, timeout <-chan time.Time) (data []byte, ok bool, err error) {
+	s.mu.Lock()
+	if len(s.pending) > 0 {
+		data, s.pending = s.pending, nil
+		s.mu.Unlock()
+		return data, true, nil
+	}
+	if s.err != nil {
+		err := s.err
+		s.mu.Unlock()
+		return nil, true, err
+	}
+	if s.inflight == nil {
+		if s.buf == nil {
+			s.buf = make([]byte, 4096)
+		}
+		s.inflight = make(chan stdoutChunk, 1)
+		go s.readInto(s.buf, s.inflight)
+	}
+	inflight := s.inflight
+	s.mu.Unlock()
+
+	select {
+	case ch := <-inflight:
+		s.mu.Lock()
+		s.inflight = nil
+		if ch.err != nil {
+			s.err = ch.err
+		}
+		s.mu.Unlock()
+		if len(ch.data) > 0 {
+			return ch.data, true, nil
+		}
+		return nil, true, ch.err
+	case <-s.done:
+		return nil, true, io.EOF
+	case <-stop:
+		return nil, false, nil
+	case <-timeout:
+		return nil, false, nil
+	}
+}
+
+// readInto performs one background read into buf
+func (s *stdoutReader) readInto(buf []byte, result chan<- stdoutChunk) {
+	n, err := s.r.Read(buf)
+	result <- stdoutChunk{buf[:n], err}
+}
+
+// unread puts data back to be returned before anything else
+func (s *stdoutReader) unread(data []byte) {
+	if len(data) == 0 {
+		return
+	}
+	s.mu.Lock()
+	s.pending = append(append([]byte(nil), data...), s.pending...)
+	s.mu.Unlock()
+}
+
+// Close wakes callers waiting on a background read. The read itself ends
+// when the underlying reader is closed.
+func (s *stdoutReader) Close() error {
+	s.once.Do(func() { close(s.done) })
+	return nil
+}
@@ -0,0 +1,108 @@
+package dgclient
+
+import (
+	"fmt"
+	"net"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// Target describes a server to connect to. Fields missing from the parsed
+// string are left empty, and Port is 0 when none was given.
+type Target struct {
+	Scheme string
+	User   string
+	Host   string
+	Port   int
+	Path   string
+}
+
+// ParseTarget parses a connection target in one of the forms
+//
+//	host
+//	host:port
+//	user@host
+//	user@host:port
+//	ssh://user@host:port/path
+//
+// IPv6 addresses may be written bare (::1) or in brackets ([::1]), and
+// must be bracketed when a port follows. Errors wrap ErrInvalidTarget.
+func ParseTarget(s string) (Target, error) {
+	if s == "" {
+		return Target{}, fmt.Errorf("%w: empty target", ErrInvalidTarget)
+	}
+	if strings.Contains(s, "://") {
+		return parseTargetURL(s)
+	}
+
+	var t Target
+	hostport := s
+	if i := strings.LastIndex(s, "@"); i >= 0 {
+		t.User, hostport = s[:i], s[i+1:]
+		if t.User == "" {
+			return Target{}, fmt.Errorf("%w: %q: empty user", ErrInvalidTarget, s)
+		}
+	}
+
+	var port string
+	switch {
+	case strings.HasPrefix(hostport, "[") && strings.HasSuffix(hostport, "]"):
+		t.Host = hostport[1 : len(hostport)-1]
+	case strings.HasPrefix(hostport, "[") || strings.Count(hostport, ":") == 1:
+		var err error
+		if t.Host, port, err = net.SplitHostPort(hostport); err != nil {
+			return Target{}, fmt.Errorf("%w: %q: %v", ErrInvalidTarget, s, err)
+		}
+		if port == "" {
+			return Target{}, fmt.Errorf("%w: %q: empty port", ErrInvalidTarget, s)
+		}
+	default:
+		// No port, or a bare IPv6 address
+		t.Host = hostport
+	}
+
+	if err := t.setPort(port); err != nil {
+		return Target{}, fmt.Errorf("%w: %q: %v", ErrInvalidTarget, s, err)
+	}
+	if t.Host == "" {
+		return Target{}, fmt.Errorf("%w: %q: empty host", ErrInvalidTarget, s)
+	}
+	return t, nil
+}
+
+// parseTargetURL parses the ssh:// URL form of a target
+func parseTargetURL(s string) (Target, error) {
+	u, err := url.Parse(s)
+	if err != nil {
+		return Target{}, fmt.Errorf("%w: %v", ErrInvalidTarget, err)
+	}
+	if u.Scheme != "ssh" {
+		return Target{}, fmt.Errorf("%w: %q: unsupported scheme %q", ErrInvalidTarget, s, u.Scheme)
+	}
+
+	t := Target{Scheme: u.Scheme, Host: u.Hostname(), Path: u.Path}
+	if u.User != nil {
+		t.User = u.User.Username()
+	}
+	if err := t.setPort(u.Port()); err != nil {
+		return Target{}, fmt.Errorf("%w: %q: %v", ErrInvalidTarget, s, err)
+	}
+	if t.Host == "" {
+		return Target{}, fmt.Errorf("%w: %q: empty host", ErrInvalidTarget, s)
+	}
+	return t, nil
+}
+
+// setPort parses and validates a port number, leaving Port 0 when empty
+func (t *Target) setPort(port string) error {
+	if port == "" {
+		return nil
+	}
+	n, err := strconv.Atoi(port)
+	if err != nil || n < 1 || n > 65535 {
+		return fmt.Errorf("invalid port %q", port)
+	}
+	t.Port = n
+	return nil
+}
@@ -0,0 +1,58 @@
+package dgclient
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestParseTarget(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected Target
+		wantErr  bool
+	}{
+		{"nethack.alt.org", Target{Host: "nethack.alt.org"}, false},
+		{"nethack.alt.org:2222", Target{Host: "nethack.alt.org", Port: 2222}, false},
+		{"nethack@nethack.alt.org", Target{User: "nethack", Host: "nethack.alt.org"}, false},
+		{"nethack@nethack.alt.org:22", Target{User: "nethack", Host: "nethack.alt.org", Port: 22}, false},
+		{"me@corp@example.com", Target{User: "me@corp", Host: "example.com"}, false},
+		{"::1", Target{Host: "::1"}, false},
+		{"[::1]", Target{Host: "::1"}, false},
+		{"[::1]:2222", Target{Host: "::1", Port: 2222}, false},
+		{"user@2001:db8::1", Target{User: "user", Host: "2001:db8::1"}, false},
+		{"user@[2001:db8::1]:22", Target{User: "user", Host: "2001:db8::1", Port: 22}, false},
+		{"ssh://host", Target{Scheme: "ssh", Host: "host"}, false},
+		{"ssh://user@host:2222", Target{Scheme: "ssh", User: "user", Host: "host", Port: 2222}, false},
+		{"ssh://user@[::1]:2222/nethack", Target{Scheme: "ssh", User: "user", Host: "::1", Port: 2222, Path: "/nethack"}, false},
+		{"", Target{}, true},
+		{"@host", Target{}, true},
+		{"user@", Target{}, true},
+		{"host:", Target{}, true},
+		{"host:ssh", Target{}, true},
+		{"host:0", Target{}, true},
+		{"host:65536", Target{}, true},
+		{"[::1", Target{}, true},
+		{"[::1]:x", Target{}, true},
+		{"telnet://host", Target{}, true},
+		{"ssh://", Target{}, true},
+		{"ssh://host:99999", Target{}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got, err := ParseTarget(tt.input)
+			if tt.wantErr {
+				if !errors.Is(err, ErrInvalidTarget) {
+					t.Errorf("Expected ErrInvalidTarget, got %v (target %+v)", err, got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if got != tt.expected {
+				t.Errorf("Expected %+v, got %+v", tt.expected, got)
+			}
+		})
+	}
+}
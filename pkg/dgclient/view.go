@@ -1,5 +1,7 @@
 package dgclient
 
+import "time"
+
 // ViewOptions contains configuration for view creation
 type ViewOptions struct {
 	// Terminal type (e.g., "xterm-256color", "vt100")
@@ -15,10 +17,26 @@ type ViewOptions struct {
 	// Unicode support
 	UnicodeEnabled bool
 
+	// What to do with input when the view's input queue is full
+	InputOverflow InputOverflowPolicy
+
 	// Custom configuration
 	Config map[string]interface{}
 }
 
+// InputOverflowPolicy selects how a view handles input arriving while its
+// input queue is full
+type InputOverflowPolicy int
+
+const (
+	// InputOverflowDrop discards the new input (default)
+	InputOverflowDrop InputOverflowPolicy = iota
+	// InputOverflowBlock waits until the queue has room or the view closes
+	InputOverflowBlock
+	// InputOverflowDropOldest discards the oldest queued input to make room
+	InputOverflowDropOldest
+)
+
 // DefaultViewOptions returns sensible defaults for view creation
 func DefaultViewOptions() ViewOptions {
 	return ViewOptions{
@@ -56,6 +74,50 @@ type View interface {
 	Close() error
 }
 
+// SizeReadyView is implemented by views whose real size is only known some
+// time after creation, such as views driven by a remote display. The client
+// waits for SizeReady to be closed, up to ClientConfig.SizeReadyTimeout,
+// before requesting the PTY so the game starts at the right size.
+type SizeReadyView interface {
+	View
+
+	// SizeReady returns a channel that is closed once GetSize reports the
+	// real display size
+	SizeReady() <-chan struct{}
+}
+
+// ResponseView is implemented by views that answer terminal queries from
+// the game, such as cursor position or window size reports. Replies are
+// written to the session unchanged: unlike HandleInput, they bypass the
+// debouncer, key sequences, input normalization and the input filter.
+type ResponseView interface {
+	View
+
+	// Responses returns a channel delivering replies to be written to the
+	// session
+	Responses() <-chan []byte
+}
+
+// ConnectionStatusView is implemented by views that display connection
+// status. The client passes every connection event to its view, in addition
+// to any handler registered with SetEventHandler.
+type ConnectionStatusView interface {
+	View
+
+	// HandleConnectionEvent is called on connect, disconnect and each
+	// reconnection attempt; it must not block
+	HandleConnectionEvent(ev ConnectionEvent)
+}
+
+// LatencyView is implemented by views that display connection latency
+type LatencyView interface {
+	View
+
+	// HandleLatency is called after each keepalive round trip with the
+	// average latency; it must not block
+	HandleLatency(latency time.Duration)
+}
+
 // ViewFactory creates View instances
 type ViewFactory interface {
 	CreateView(opts ViewOptions) (View, error)
@@ -0,0 +1,28 @@
+package dgclient
+
+// welcomeReset precedes the first server output after a welcome message.
+// A full reset (RIS) clears the message along with any attributes,
+// character sets or scroll region it changed.
+const welcomeReset = "\x1bc"
+
+// showWelcome renders the configured welcome message, if any, to a newly
+// set view
+func (c *Client) showWelcome(view View) error {
+	if len(c.config.WelcomeMessage) == 0 {
+		return nil
+	}
+	if err := view.Render(c.config.WelcomeMessage); err != nil {
+		return err
+	}
+	c.welcomePending.Store(true)
+	return nil
+}
+
+// clearWelcome prefixes the first output rendered after a welcome message
+// with a terminal reset so the message is cleared
+func (c *Client) clearWelcome(data []byte) []byte {
+	if !c.welcomePending.CompareAndSwap(true, false) {
+		return data
+	}
+	return append([]byte(welcomeReset), data...)
+}
@@ -0,0 +1,52 @@
+package dgclient
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+func TestWelcomeMessageShownThenCleared(t *testing.T) {
+	for _, buffered := range []bool{false, true} {
+		config := DefaultClientConfig()
+		config.WelcomeMessage = []byte("\x1b[1mConnecting to HDF\x1b[0m")
+		if buffered {
+			config.OutputBufferSize = 4096
+		}
+		client := NewClient(config)
+		view := newScriptedView()
+		close(view.inputCh)
+
+		if err := client.SetView(view); err != nil {
+			t.Fatalf("SetView() failed: %v", err)
+		}
+		if got := view.Rendered(); got != string(config.WelcomeMessage) {
+			t.Errorf("Expected welcome message before output, got %q", got)
+		}
+
+		client.session = &fakeSession{stdout: strings.NewReader("game")}
+		if err := client.runSession(context.Background()); err != nil {
+			t.Fatalf("runSession() failed: %v", err)
+		}
+
+		want := string(config.WelcomeMessage) + welcomeReset + "game"
+		if got := view.Rendered(); got != want {
+			t.Errorf("Expected welcome cleared before first output (buffered=%v), got %q", buffered, got)
+		}
+		client.Close()
+	}
+}
+
+func TestNoWelcomeMessageByDefault(t *testing.T) {
+	client := NewClient(nil)
+	defer client.Close()
+	view := newScriptedView()
+
+	client.SetView(view)
+	if got := view.Rendered(); got != "" {
+		t.Errorf("Expected nothing rendered, got %q", got)
+	}
+	if got := string(client.clearWelcome([]byte("x"))); got != "x" {
+		t.Errorf("Expected output unchanged, got %q", got)
+	}
+}
@@ -0,0 +1,117 @@
+package dgclient
+
+import (
+	"io"
+	"sync"
+	"time"
+)
+
+// coalescingWriter batches bursts of small writes into fewer larger writes.
+// A write arriving while the writer is idle is passed through immediately so
+// interactive keystrokes keep their latency; writes arriving within the flush
+// interval of a previous write are buffered until the interval elapses or the
+// buffer reaches the threshold.
+type coalescingWriter struct {
+	w         io.Writer
+	interval  time.Duration
+	threshold int
+
+	mu        sync.Mutex
+	buf       []byte
+	lastWrite time.Time
+	timer     *time.Timer
+	err       error
+	closed    bool
+}
+
+// newCoalescingWriter wraps w with a coalescing strategy
+func newCoalescingWriter(w io.Writer, interval time.Duration, threshold int) *coalescingWriter {
+	if threshold <= 0 {
+		threshold = 4096
+	}
+	return &coalescingWriter{
+		w:         w,
+		interval:  interval,
+		threshold: threshold,
+	}
+}
+
+// Write implements io.Writer
+func (cw *coalescingWriter) Write(p []byte) (int, error) {
+	cw.mu.Lock()
+	defer cw.mu.Unlock()
+
+	if cw.err != nil {
+		return 0, cw.err
+	}
+	if cw.closed {
+		return 0, io.ErrClosedPipe
+	}
+
+	now := time.Now()
+	idle := len(cw.buf) == 0 && now.Sub(cw.lastWrite) >= cw.interval
+	cw.lastWrite = now
+
+	// Lone keystroke: send it right away
+	if idle {
+		if _, err := cw.w.Write(p); err != nil {
+			cw.err = err
+			return 0, err
+		}
+		return len(p), nil
+	}
+
+	cw.buf = append(cw.buf, p...)
+	if len(cw.buf) >= cw.threshold {
+		if err := cw.flushLocked(); err != nil {
+			return 0, err
+		}
+		return len(p), nil
+	}
+
+	if cw.timer == nil {
+		cw.timer = time.AfterFunc(cw.interval, cw.timedFlush)
+	}
+	return len(p), nil
+}
+
+// timedFlush is invoked by the timer once a burst window elapses
+func (cw *coalescingWriter) timedFlush() {
+	cw.mu.Lock()
+	defer cw.mu.Unlock()
+	cw.timer = nil
+	cw.flushLocked()
+}
+
+// Flush writes any buffered data immediately
+func (cw *coalescingWriter) Flush() error {
+	cw.mu.Lock()
+	defer cw.mu.Unlock()
+	return cw.flushLocked()
+}
+
+func (cw *coalescingWriter) flushLocked() error {
+	if cw.timer != nil {
+		cw.timer.Stop()
+		cw.timer = nil
+	}
+	if len(cw.buf) == 0 || cw.err != nil {
+		return cw.err
+	}
+
+	_, err := cw.w.Write(cw.buf)
+	cw.buf = cw.buf[:0]
+	if err != nil {
+		cw.err = err
+	}
+	return err
+}
+
+// Close flushes pending data and stops the writer. The underlying writer is not closed.
+func (cw *coalescingWriter) Close() error {
+	cw.mu.Lock()
+	defer cw.mu.Unlock()
+	err := cw.flushLocked()
+	cw.closed = true
+	return err
+}
@@ -0,0 +1,84 @@
+package dgclient
+
+import (
+	"bytes"
+	"sync"
+	"testing"
+	"time"
+)
+
+// recordingWriter records each Write call separately
+type recordingWriter struct {
+	mu     sync.Mutex
+	writes [][]byte
+}
+
+func (r *recordingWriter) Write(p []byte) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.writes = append(r.writes, append([]byte(nil), p...))
+	return len(p), nil
+}
+
+func (r *recordingWriter) Writes() [][]byte {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([][]byte(nil), r.writes...)
+}
+
+func TestCoalescingWriterLoneKeystroke(t *testing.T) {
+	rec := &recordingWriter{}
+	cw := newCoalescingWriter(rec, 50*time.Millisecond, 0)
+	defer cw.Close()
+
+	if _, err := cw.Write([]byte("k")); err != nil {
+		t.Fatalf("Write() failed: %v", err)
+	}
+
+	writes := rec.Writes()
+	if len(writes) != 1 || string(writes[0]) != "k" {
+		t.Errorf("Expected lone keystroke to be flushed immediately, got %q", writes)
+	}
+}
+
+func TestCoalescingWriterBatchesBurst(t *testing.T) {
+	rec := &recordingWriter{}
+	cw := newCoalescingWriter(rec, 50*time.Millisecond, 0)
+	defer cw.Close()
+
+	for _, s := range []string{"a", "b", "c", "d"} {
+		if _, err := cw.Write([]byte(s)); err != nil {
+			t.Fatalf("Write() failed: %v", err)
+		}
+	}
+
+	// First byte passes straight through, the rest is buffered
+	if writes := rec.Writes(); len(writes) != 1 {
+		t.Fatalf("Expected 1 write before flush interval, got %d", len(writes))
+	}
+
+	time.Sleep(150 * time.Millisecond)
+
+	writes := rec.Writes()
+	if len(writes) != 2 {
+		t.Fatalf("Expected burst to be coalesced into 2 writes, got %d: %q", len(writes), writes)
+	}
+	if string(writes[1]) != "bcd" {
+		t.Errorf("Expected batched write 'bcd', got %q", writes[1])
+	}
+}
+
+func TestCoalescingWriterThreshold(t *testing.T) {
+	rec := &recordingWriter{}
+	cw := newCoalescingWriter(rec, time.Hour, 4)
+	defer cw.Close()
+
+	cw.Write([]byte("x"))
+	cw.Write([]byte("12"))
+	cw.Write([]byte("34"))
+
+	writes := rec.Writes()
+	if len(writes) != 2 || !bytes.Equal(writes[1], []byte("1234")) {
+		t.Errorf("Expected threshold flush of '1234', got %q", writes)
+	}
+}
@@ -0,0 +1,95 @@
+package tui
+
+import "sort"
+
+// CellChange is a cell whose content changed since the last GetChanges call
+type CellChange struct {
+	X, Y int
+	Cell Cell
+}
+
+// changeSet records which cells were written while change tracking is on.
+// dirty is indexed by y*width+x; list holds the dirty indices in write order.
+type changeSet struct {
+	width int
+	dirty []bool
+	list  []int
+}
+
+func newChangeSet(width, height int) *changeSet {
+	return &changeSet{width: width, dirty: make([]bool, width*height)}
+}
+
+func (cs *changeSet) mark(x, y int) {
+	i := y*cs.width + x
+	if !cs.dirty[i] {
+		cs.dirty[i] = true
+		cs.list = append(cs.list, i)
+	}
+}
+
+func (cs *changeSet) markRow(y int) {
+	for x := 0; x < cs.width; x++ {
+		cs.mark(x, y)
+	}
+}
+
+func (cs *changeSet) markAll() {
+	for i := range cs.dirty {
+		if !cs.dirty[i] {
+			cs.dirty[i] = true
+			cs.list = append(cs.list, i)
+		}
+	}
+}
+
+// SetChangeTracking enables or disables recording of changed cells for
+// GetChanges. Tracking starts with an empty change set, so callers should
+// take a full snapshot with CopyScreen when enabling it.
+func (te *TerminalEmulator) SetChangeTracking(enabled bool) {
+	te.mu.Lock()
+	defer te.mu.Unlock()
+	if enabled {
+		te.changes = newChangeSet(te.width, te.height)
+	} else {
+		te.changes = nil
+	}
+}
+
+// GetChanges returns the cells changed since the previous call in row-major
+// order and clears the change set. It returns nil when tracking is disabled.
+// A resize reports every cell as changed.
+func (te *TerminalEmulator) GetChanges() []CellChange {
+	te.mu.Lock()
+	defer te.mu.Unlock()
+
+	cs := te.changes
+	if cs == nil || len(cs.list) == 0 {
+		return nil
+	}
+
+	sort.Ints(cs.list)
+	changes := make([]CellChange, len(cs.list))
+	for n, i := range cs.list {
+		x, y := i%cs.width, i/cs.width
+		changes[n] = CellChange{X: x, Y: y, Cell: te.screen[y][x]}
+		cs.dirty[i] = false
+	}
+	cs.list = cs.list[:0]
+	return changes
+}
+
+// setCell writes a cell and records the change when tracking is enabled
+func (te *TerminalEmulator) setCell(x, y int, c Cell) {
+	te.screen[y][x] = c
+	if te.changes != nil {
+		te.changes.mark(x, y)
+	}
+}
+
+// markRowChanged records a whole row as changed when tracking is enabled
+func (te *TerminalEmulator) markRowChanged(y int) {
+	if te.changes != nil {
+		te.changes.markRow(y)
+	}
+}
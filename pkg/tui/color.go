@@ -0,0 +1,33 @@
+package tui
+
+import (
+	"fmt"
+
+	"github.com/gdamore/tcell/v2"
+)
+
+// ColorToHex returns c as a "#RRGGBB" string. Indexed colors are resolved
+// through the xterm 256-color palette (16 base colors, 6x6x6 cube and
+// grayscale ramp) as used by tcell, so web renderers match the TUI exactly.
+func ColorToHex(c Color) string {
+	r, g, b := c.RGB()
+	return fmt.Sprintf("#%02X%02X%02X", r, g, b)
+}
+
+// RGB returns the red, green and blue components of c, resolving indexed
+// colors through the 256-color palette
+func (c Color) RGB() (r, g, b uint8) {
+	if !c.IsIndex {
+		return c.R, c.G, c.B
+	}
+	r32, g32, b32 := tcell.PaletteColor(int(c.Index)).RGB()
+	return uint8(r32), uint8(g32), uint8(b32)
+}
+
+// tcellColor converts c to a tcell color, keeping indexed colors indexed
+func tcellColor(c Color) tcell.Color {
+	if c.IsIndex {
+		return tcell.PaletteColor(int(c.Index))
+	}
+	return tcell.NewRGBColor(int32(c.R), int32(c.G), int32(c.B))
+}
@@ -0,0 +1,27 @@
+package tui
+
+import "testing"
+
+func TestColorToHex(t *testing.T) {
+	tests := []struct {
+		color Color
+		want  string
+	}{
+		{Color{R: 255, G: 255, B: 255}, "#FFFFFF"},
+		{Color{R: 0x12, G: 0xab, B: 0x05}, "#12AB05"},
+		{Color{IsIndex: true, Index: 1}, "#800000"},
+		{Color{IsIndex: true, Index: 9}, "#FF0000"},
+		{Color{IsIndex: true, Index: 16}, "#000000"},
+		{Color{IsIndex: true, Index: 67}, "#5F87AF"},
+		{Color{IsIndex: true, Index: 231}, "#FFFFFF"},
+		{Color{IsIndex: true, Index: 232}, "#080808"},
+		{Color{IsIndex: true, Index: 244}, "#808080"},
+		{Color{IsIndex: true, Index: 255}, "#EEEEEE"},
+	}
+
+	for _, tt := range tests {
+		if got := ColorToHex(tt.color); got != tt.want {
+			t.Errorf("ColorToHex(%+v) = %s, want %s", tt.color, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,118 @@
+package tui
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"gopkg.in/yaml.v3"
+)
+
+// conformanceCase is one emulator conformance check loaded from
+// testdata/conformance. When Screen is given, rows are compared with
+// trailing spaces trimmed and rows beyond those listed must be blank.
+type conformanceCase struct {
+	Name   string              `yaml:"name"`
+	Size   [2]int              `yaml:"size"`
+	Input  string              `yaml:"input"`
+	Screen []string            `yaml:"screen"`
+	Cursor *[2]int             `yaml:"cursor"`
+	Attrs  []conformanceAttrAt `yaml:"attrs"`
+}
+
+// conformanceAttrAt describes the expected attributes of the cell at At
+// ([x, y]). Unset fields are not checked; colors are "#RRGGBB".
+type conformanceAttrAt struct {
+	At        [2]int `yaml:"at"`
+	Fg        string `yaml:"fg"`
+	Bg        string `yaml:"bg"`
+	Bold      *bool  `yaml:"bold"`
+	Underline *bool  `yaml:"underline"`
+	Reverse   *bool  `yaml:"reverse"`
+}
+
+func loadConformanceCases(t *testing.T) map[string][]conformanceCase {
+	t.Helper()
+	files, err := filepath.Glob("testdata/conformance/*.yaml")
+	if err != nil || len(files) == 0 {
+		t.Fatalf("No conformance cases found: %v", err)
+	}
+
+	suites := make(map[string][]conformanceCase)
+	for _, file := range files {
+		f, err := os.Open(file)
+		if err != nil {
+			t.Fatalf("Failed to open %s: %v", file, err)
+		}
+		// Reject unknown keys so a misspelt expectation cannot pass silently
+		dec := yaml.NewDecoder(f)
+		dec.KnownFields(true)
+		var cases []conformanceCase
+		err = dec.Decode(&cases)
+		f.Close()
+		if err != nil {
+			t.Fatalf("Failed to parse %s: %v", file, err)
+		}
+		suites[strings.TrimSuffix(filepath.Base(file), ".yaml")] = cases
+	}
+	return suites
+}
+
+// TestConformance runs the data-driven cases in testdata/conformance. Add a
+// case to the matching YAML file to cover a new sequence or regression.
+func TestConformance(t *testing.T) {
+	for suite, cases := range loadConformanceCases(t) {
+		for _, tc := range cases {
+			t.Run(suite+"/"+tc.Name, func(t *testing.T) {
+				runConformanceCase(t, tc)
+			})
+		}
+	}
+}
+
+func runConformanceCase(t *testing.T, tc conformanceCase) {
+	width, height := tc.Size[0], tc.Size[1]
+	if width == 0 || height == 0 {
+		width, height = 20, 5
+	}
+	te := NewTerminalEmulator(width, height)
+	te.ProcessData([]byte(tc.Input))
+
+	for y := 0; tc.Screen != nil && y < height; y++ {
+		want := ""
+		if y < len(tc.Screen) {
+			want = tc.Screen[y]
+		}
+		if got := rowString(te, y); got != want {
+			t.Errorf("Row %d: expected %q, got %q", y, want, got)
+		}
+	}
+
+	if tc.Cursor != nil {
+		if x, y := te.GetCursor(); x != tc.Cursor[0] || y != tc.Cursor[1] {
+			t.Errorf("Expected cursor at %v, got [%d, %d]", *tc.Cursor, x, y)
+		}
+	}
+
+	screen := te.GetScreen()
+	for _, want := range tc.Attrs {
+		x, y := want.At[0], want.At[1]
+		attr := screen[y][x].Attr
+		if want.Fg != "" && ColorToHex(attr.Foreground) != want.Fg {
+			t.Errorf("Cell %v: expected fg %s, got %s", want.At, want.Fg, ColorToHex(attr.Foreground))
+		}
+		if want.Bg != "" && ColorToHex(attr.Background) != want.Bg {
+			t.Errorf("Cell %v: expected bg %s, got %s", want.At, want.Bg, ColorToHex(attr.Background))
+		}
+		if want.Bold != nil && attr.Bold != *want.Bold {
+			t.Errorf("Cell %v: expected bold %v, got %v", want.At, *want.Bold, attr.Bold)
+		}
+		if want.Underline != nil && attr.Underline != *want.Underline {
+			t.Errorf("Cell %v: expected underline %v, got %v", want.At, *want.Underline, attr.Underline)
+		}
+		if want.Reverse != nil && attr.Reverse != *want.Reverse {
+			t.Errorf("Cell %v: expected reverse %v, got %v", want.At, *want.Reverse, attr.Reverse)
+		}
+	}
+}
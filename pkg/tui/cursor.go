@@ -0,0 +1,52 @@
+package tui
+
+// maxSavedCursors bounds the DECSC stack; saving beyond it discards the
+// oldest entry
+const maxSavedCursors = 16
+
+// savedCursor is the cursor state kept by DECSC: position, rendition,
+// character set selection and origin mode
+type savedCursor struct {
+	x, y          int
+	attr          CellAttributes
+	charsets      [2]Charset
+	activeCharset int
+	originMode    bool
+}
+
+// saveCursor pushes the cursor state for restoreCursor, so a routine that
+// saves the cursor inside a caller's save/restore pair does not clobber it
+func (te *TerminalEmulator) saveCursor() {
+	if len(te.savedCursors) == maxSavedCursors {
+		te.savedCursors = append(te.savedCursors[:0], te.savedCursors[1:]...)
+	}
+	te.savedCursors = append(te.savedCursors, savedCursor{
+		x:             te.cursorX,
+		y:             te.cursorY,
+		attr:          te.currentAttr,
+		charsets:      te.charsets,
+		activeCharset: te.activeCharset,
+		originMode:    te.originMode,
+	})
+}
+
+// restoreCursor reinstates the most recently saved state, clamping the
+// position to the current screen size. The last entry is kept rather than
+// popped, so repeated restores after a single save return to the same place
+// as on a single-slot terminal. With nothing saved it homes the cursor with
+// default attributes.
+func (te *TerminalEmulator) restoreCursor() {
+	sc := savedCursor{attr: defaultAttr}
+	if n := len(te.savedCursors); n > 0 {
+		sc = te.savedCursors[n-1]
+		if n > 1 {
+			te.savedCursors = te.savedCursors[:n-1]
+		}
+	}
+	te.cursorX = min(sc.x, te.width-1)
+	te.cursorY = min(sc.y, te.height-1)
+	te.currentAttr = sc.attr
+	te.charsets = sc.charsets
+	te.activeCharset = sc.activeCharset
+	te.originMode = sc.originMode
+}
@@ -1,7 +1,31 @@
 package tui
 
 import (
+	"encoding/base64"
+	"fmt"
+	"strings"
 	"sync"
+	"unicode/utf8"
+
+	"github.com/opd-ai/go-gamelaunch-client/pkg/dgclient"
+)
+
+// Default upper bounds for terminal dimensions, guarding against oversized
+// resize requests allocating huge screen buffers
+const (
+	DefaultMaxWidth  = 1000
+	DefaultMaxHeight = 1000
+)
+
+// Parser limits bounding the memory an untrusted stream can make the parser
+// hold. Excess parameters and intermediates are ignored, parameter values
+// saturate and oversized OSC strings are discarded.
+const (
+	maxCSIParams        = 32
+	maxCSIParamValue    = 65535
+	maxCSIIntermediates = 4
+	maxOSCLength        = 1 << 20
+	maxDCSLength        = 4 << 20
 )
 
 // TerminalEmulator provides a proper terminal emulation layer
@@ -16,8 +40,9 @@ type TerminalEmulator struct {
 	// Cursor position
 	cursorX, cursorY int
 
-	// Terminal state
-	savedCursorX, savedCursorY int
+	// Cursor states saved by DECSC (ESC 7) and restored by DECRC (ESC 8),
+	// most recent last
+	savedCursors []savedCursor
 
 	// Parser state for ANSI sequences
 	parser *AnsiParser
@@ -27,12 +52,82 @@ type TerminalEmulator struct {
 
 	// Character attributes
 	currentAttr CellAttributes
+
+	// Cursor shape (DECSCUSR)
+	cursorStyle CursorStyle
+
+	// Character sets designated to G0 and G1, and the one shifted in
+	charsets      [2]Charset
+	activeCharset int
+
+	// Resize limits
+	maxWidth, maxHeight int
+
+	// Interpret 8-bit C1 control bytes (0x80-0x9F)
+	c1Controls bool
+
+	// DEC private modes (see privateModes). mainScreen holds the primary
+	// screen while the alternate screen is active.
+	bracketedPaste bool
+	appCursorKeys  bool
+	autoWrap       bool
+	cursorHidden   bool
+	mainScreen     [][]Cell
+
+	// Origin mode (DECOM): cursor addressing is relative to the scrolling
+	// region and confined to it
+	originMode bool
+
+	// ANSI modes (see ansiModes)
+	newlineMode bool
+
+	// Window title (OSC 0/2)
+	title        string
+	titleHandler TitleHandler
+
+	// Clipboard handling (OSC 52)
+	clipboardHandler ClipboardHandler
+
+	// Hyperlinks (OSC 8); currentLink is applied to written cells
+	links       linkTable
+	currentLink uint32
+
+	// Device control strings such as sixel images
+	dcsHandler DCSHandler
+
+	// Replies to terminal queries such as window size reports
+	responseHandler ResponseHandler
+
+	// Cells changed since the last GetChanges, nil unless tracking
+	changes *changeSet
+
+	// Callbacks queued while parsing, run once the lock is released
+	pendingEvents []func()
 }
 
+// TitleHandler is called when the remote side changes the window title via OSC 0 or 2
+type TitleHandler func(title string)
+
+// ClipboardHandler is called when the remote side sets the clipboard via OSC 52.
+// selection holds the OSC 52 selection parameter (e.g. "c", "p"), data the decoded payload.
+type ClipboardHandler func(selection string, data []byte)
+
+// DCSHandler is called with the body of each complete device control string
+// (the bytes between ESC P and the string terminator), for example a sixel
+// image starting with its parameters and 'q'. The slice is owned by the
+// handler.
+type DCSHandler func(data []byte)
+
+// ResponseHandler is called with the emulator's replies to queries from the
+// remote side, such as window size reports. The bytes should be sent to the
+// server as if typed.
+type ResponseHandler func(data []byte)
+
 // Cell represents a single character cell with attributes
 type Cell struct {
 	Char rune
 	Attr CellAttributes
+	Link uint32 // OSC 8 hyperlink ID for LinkURL, 0 for none
 }
 
 // CellAttributes stores text formatting information
@@ -44,6 +139,9 @@ type CellAttributes struct {
 	Reverse    bool
 }
 
+// defaultAttr is the rendition after a reset: white on the default background
+var defaultAttr = CellAttributes{Foreground: Color{R: 255, G: 255, B: 255}}
+
 // Color represents a terminal color
 type Color struct {
 	R, G, B uint8
@@ -53,12 +151,50 @@ type Color struct {
 
 // AnsiParser handles ANSI escape sequence parsing
 type AnsiParser struct {
-	state      ParserState
-	buffer     []byte
-	params     []int
-	paramIndex int
+	state         ParserState
+	buffer        []byte
+	params        []int
+	paramIndex    int
+	intermediates []byte
+	private       byte
+	oscOverflow   bool
+	dcsOverflow   bool
+	charsetSlot   int
+	utf8Buf       []byte
+	utf8Need      int
+}
+
+// Charset is a character set that can be designated to G0 or G1
+type Charset int
+
+const (
+	CharsetASCII Charset = iota
+	CharsetDECSpecialGraphics
+)
+
+// decSpecialGraphics maps DEC special graphics characters to Unicode
+var decSpecialGraphics = map[rune]rune{
+	'_': ' ', '`': '◆', 'a': '▒', 'b': '␉', 'c': '␌', 'd': '␍', 'e': '␊',
+	'f': '°', 'g': '±', 'h': '␤', 'i': '␋', 'j': '┘', 'k': '┐', 'l': '┌',
+	'm': '└', 'n': '┼', 'o': '⎺', 'p': '⎻', 'q': '─', 'r': '⎼', 's': '⎽',
+	't': '├', 'u': '┤', 'v': '┴', 'w': '┬', 'x': '│', 'y': '≤', 'z': '≥',
+	'{': 'π', '|': '≠', '}': '£', '~': '·',
 }
 
+// CursorStyle is the cursor shape set with DECSCUSR (CSI Ps SP q).
+// Values match the DECSCUSR parameter.
+type CursorStyle int
+
+const (
+	CursorStyleDefault CursorStyle = iota
+	CursorStyleBlinkingBlock
+	CursorStyleSteadyBlock
+	CursorStyleBlinkingUnderline
+	CursorStyleSteadyUnderline
+	CursorStyleBlinkingBar
+	CursorStyleSteadyBar
+)
+
 type ParserState int
 
 const (
@@ -66,6 +202,8 @@ const (
 	StateEscape
 	StateCSI
 	StateOSC
+	StateCharset
+	StateDCS
 )
 
 // NewTerminalEmulator creates a new terminal emulator
@@ -76,7 +214,11 @@ func NewTerminalEmulator(width, height int) *TerminalEmulator {
 		screen:       make([][]Cell, height),
 		parser:       &AnsiParser{state: StateNormal},
 		scrollBottom: height - 1,
-		currentAttr:  CellAttributes{Foreground: Color{R: 255, G: 255, B: 255}},
+		currentAttr:  defaultAttr,
+		cursorStyle:  CursorStyleSteadyBlock,
+		autoWrap:     true,
+		maxWidth:     DefaultMaxWidth,
+		maxHeight:    DefaultMaxHeight,
 	}
 
 	// Initialize screen buffer
@@ -90,14 +232,58 @@ func NewTerminalEmulator(width, height int) *TerminalEmulator {
 	return te
 }
 
-// ProcessData processes incoming terminal data and updates the screen
-func (te *TerminalEmulator) ProcessData(data []byte) {
+// SetClipboardHandler registers a handler for OSC 52 clipboard set requests.
+// Clipboard read requests are always ignored.
+func (te *TerminalEmulator) SetClipboardHandler(handler ClipboardHandler) {
 	te.mu.Lock()
 	defer te.mu.Unlock()
+	te.clipboardHandler = handler
+}
 
+// ProcessData processes incoming terminal data and updates the screen
+func (te *TerminalEmulator) ProcessData(data []byte) {
+	te.mu.Lock()
 	for _, b := range data {
 		te.processByte(b)
 	}
+	events := te.pendingEvents
+	te.pendingEvents = nil
+	te.mu.Unlock()
+
+	// Dispatch callbacks without holding the lock so handlers may query the emulator
+	for _, event := range events {
+		event()
+	}
+}
+
+// SetDCSHandler registers a handler for device control strings such as
+// sixel images. Without a handler they are consumed and discarded.
+func (te *TerminalEmulator) SetDCSHandler(handler DCSHandler) {
+	te.mu.Lock()
+	defer te.mu.Unlock()
+	te.dcsHandler = handler
+}
+
+// SetResponseHandler registers a handler for replies to terminal queries.
+// Without a handler queries go unanswered.
+func (te *TerminalEmulator) SetResponseHandler(handler ResponseHandler) {
+	te.mu.Lock()
+	defer te.mu.Unlock()
+	te.responseHandler = handler
+}
+
+// SetTitleHandler registers a handler for window title changes
+func (te *TerminalEmulator) SetTitleHandler(handler TitleHandler) {
+	te.mu.Lock()
+	defer te.mu.Unlock()
+	te.titleHandler = handler
+}
+
+// GetTitle returns the window title last set by the remote side
+func (te *TerminalEmulator) GetTitle() string {
+	te.mu.RLock()
+	defer te.mu.RUnlock()
+	return te.title
 }
 
 // processByte processes a single byte through the ANSI parser
@@ -111,19 +297,38 @@ func (te *TerminalEmulator) processByte(b byte) {
 		te.processCSIByte(b)
 	case StateOSC:
 		te.processOSCByte(b)
+	case StateCharset:
+		te.processCharsetByte(b)
+	case StateDCS:
+		te.processDCSByte(b)
 	}
 }
 
-// processNormalByte handles normal characters and escape sequences
+// processNormalByte handles normal characters and escape sequences.
+// C0 control bytes are never printed: those listed below act on the cursor
+// or charset, and the rest, including NUL and DEL, are ignored.
 func (te *TerminalEmulator) processNormalByte(b byte) {
+	if te.parser.utf8Need > 0 {
+		if b >= 0x80 && b <= 0xBF {
+			// UTF-8 continuation, even where the byte doubles as a C1 control
+			te.printByte(b)
+			return
+		}
+		te.abortUTF8()
+	}
+
 	switch b {
 	case 0x1B: // ESC
 		te.parser.state = StateEscape
 		te.parser.buffer = te.parser.buffer[:0]
 	case '\r': // Carriage Return
 		te.cursorX = 0
-	case '\n': // Line Feed
-		te.newline()
+	case '\n', '\v', '\f': // Line Feed, Vertical Tab, Form Feed; also a carriage return in newline mode (LNM)
+		if te.newlineMode {
+			te.newline()
+		} else {
+			te.index()
+		}
 	case '\b': // Backspace
 		if te.cursorX > 0 {
 			te.cursorX--
@@ -135,11 +340,76 @@ func (te *TerminalEmulator) processNormalByte(b byte) {
 		}
 	case 7: // Bell
 		// Ignore bell for now
+	case 0x00, 0x7F: // NUL (padding), DEL
+		// No-op; DEL in particular must not be printed
+	case 0x84: // IND (C1)
+		if te.c1Controls {
+			te.index()
+			return
+		}
+		te.printByte(b)
+	case 0x85: // NEL (C1)
+		if te.c1Controls {
+			te.newline()
+			return
+		}
+		te.printByte(b)
+	case 0x8D: // RI (C1)
+		if te.c1Controls {
+			te.reverseNewline()
+			return
+		}
+		te.printByte(b)
+	case 0x0E: // Shift Out: use G1
+		te.activeCharset = 1
+	case 0x0F: // Shift In: use G0
+		te.activeCharset = 0
 	default:
-		if b >= 32 { // Printable character
-			te.putChar(rune(b))
+		// Remaining C0 controls (ENQ, SUB, CAN, ...) are ignored
+		if b >= 32 {
+			te.printByte(b)
+		}
+	}
+}
+
+// printByte decodes printable bytes as UTF-8 and places each complete
+// character. Invalid bytes are shown as U+FFFD.
+func (te *TerminalEmulator) printByte(b byte) {
+	p := te.parser
+	if p.utf8Need > 0 {
+		p.utf8Buf = append(p.utf8Buf, b)
+		if len(p.utf8Buf) == p.utf8Need {
+			r, _ := utf8.DecodeRune(p.utf8Buf)
+			p.utf8Buf = p.utf8Buf[:0]
+			p.utf8Need = 0
+			te.putChar(r)
 		}
+		return
 	}
+
+	switch {
+	case b < 0x80:
+		te.putChar(rune(b))
+	case b >= 0xC2 && b <= 0xDF:
+		p.utf8Need = 2
+	case b >= 0xE0 && b <= 0xEF:
+		p.utf8Need = 3
+	case b >= 0xF0 && b <= 0xF4:
+		p.utf8Need = 4
+	default:
+		te.putChar(utf8.RuneError)
+		return
+	}
+	if p.utf8Need > 0 {
+		p.utf8Buf = append(p.utf8Buf[:0], b)
+	}
+}
+
+// abortUTF8 drops an incomplete UTF-8 sequence, marking it with U+FFFD
+func (te *TerminalEmulator) abortUTF8() {
+	te.parser.utf8Buf = te.parser.utf8Buf[:0]
+	te.parser.utf8Need = 0
+	te.putChar(utf8.RuneError)
 }
 
 // processEscapeByte handles escape sequence detection
@@ -149,24 +419,39 @@ func (te *TerminalEmulator) processEscapeByte(b byte) {
 		te.parser.state = StateCSI
 		te.parser.params = te.parser.params[:0]
 		te.parser.paramIndex = 0
+		te.parser.intermediates = te.parser.intermediates[:0]
+		te.parser.private = 0
 	case ']':
 		te.parser.state = StateOSC
+		te.parser.buffer = te.parser.buffer[:0]
+		te.parser.oscOverflow = false
+	case 'P': // Device Control String
+		te.parser.state = StateDCS
+		te.parser.buffer = te.parser.buffer[:0]
+		te.parser.dcsOverflow = false
+	case '(', ')': // Designate G0 or G1 character set
+		te.parser.state = StateCharset
+		te.parser.charsetSlot = 0
+		if b == ')' {
+			te.parser.charsetSlot = 1
+		}
 	case 'c': // Reset
 		te.reset()
 		te.parser.state = StateNormal
 	case 'D': // Index (move down)
+		te.index()
+		te.parser.state = StateNormal
+	case 'E': // Next Line (CR+LF)
 		te.newline()
 		te.parser.state = StateNormal
 	case 'M': // Reverse Index (move up)
 		te.reverseNewline()
 		te.parser.state = StateNormal
-	case '7': // Save cursor
-		te.savedCursorX = te.cursorX
-		te.savedCursorY = te.cursorY
+	case '7': // Save cursor (DECSC)
+		te.saveCursor()
 		te.parser.state = StateNormal
-	case '8': // Restore cursor
-		te.cursorX = te.savedCursorX
-		te.cursorY = te.savedCursorY
+	case '8': // Restore cursor (DECRC)
+		te.restoreCursor()
 		te.parser.state = StateNormal
 	default:
 		te.parser.state = StateNormal
@@ -177,26 +462,148 @@ func (te *TerminalEmulator) processEscapeByte(b byte) {
 func (te *TerminalEmulator) processCSIByte(b byte) {
 	if b >= '0' && b <= '9' {
 		// Build parameter
-		if len(te.parser.params) <= te.parser.paramIndex {
+		if te.parser.paramIndex >= maxCSIParams {
+			return
+		}
+		for len(te.parser.params) <= te.parser.paramIndex {
 			te.parser.params = append(te.parser.params, 0)
 		}
-		te.parser.params[te.parser.paramIndex] = te.parser.params[te.parser.paramIndex]*10 + int(b-'0')
+		value := te.parser.params[te.parser.paramIndex]*10 + int(b-'0')
+		if value > maxCSIParamValue {
+			value = maxCSIParamValue
+		}
+		te.parser.params[te.parser.paramIndex] = value
 	} else if b == ';' {
 		// Parameter separator
-		te.parser.paramIndex++
+		if te.parser.paramIndex < maxCSIParams {
+			te.parser.paramIndex++
+		}
+	} else if b >= 0x3C && b <= 0x3F {
+		// Private parameter marker (e.g. '?' in DECSET), only valid first
+		if len(te.parser.params) == 0 && te.parser.private == 0 {
+			te.parser.private = b
+		}
+	} else if b >= 0x20 && b <= 0x2F {
+		// Intermediate byte (e.g. SP in DECSCUSR)
+		if len(te.parser.intermediates) < maxCSIIntermediates {
+			te.parser.intermediates = append(te.parser.intermediates, b)
+		}
 	} else {
 		// Command character
-		te.executeCSICommand(b)
+		if te.parser.private != 0 {
+			te.executePrivateCSICommand(b)
+		} else if len(te.parser.intermediates) > 0 {
+			te.executeCSIIntermediateCommand(b)
+		} else {
+			te.executeCSICommand(b)
+		}
 		te.parser.state = StateNormal
 	}
 }
 
+// processCharsetByte completes a G0/G1 character set designation
+func (te *TerminalEmulator) processCharsetByte(b byte) {
+	switch b {
+	case '0':
+		te.charsets[te.parser.charsetSlot] = CharsetDECSpecialGraphics
+	default: // 'B' (US ASCII) and unsupported national sets
+		te.charsets[te.parser.charsetSlot] = CharsetASCII
+	}
+	te.parser.state = StateNormal
+}
+
 // processOSCByte handles OSC (Operating System Command) sequences
 func (te *TerminalEmulator) processOSCByte(b byte) {
 	if b == 7 || b == 0x1B { // BEL or ESC terminates OSC
+		if !te.parser.oscOverflow {
+			te.executeOSCCommand(string(te.parser.buffer))
+		}
+		te.parser.buffer = te.parser.buffer[:0]
+		te.parser.oscOverflow = false
 		te.parser.state = StateNormal
+		if b == 0x1B {
+			// ESC \ (ST): consume the trailing byte as an escape sequence
+			te.parser.state = StateEscape
+		}
+		return
+	}
+	if len(te.parser.buffer) >= maxOSCLength {
+		te.parser.oscOverflow = true
+		return
+	}
+	te.parser.buffer = append(te.parser.buffer, b)
+}
+
+// processDCSByte collects a device control string until ESC \ or the 8-bit
+// ST (0x9C). DCS payloads are 7-bit, so 0x9C is unambiguous here even when
+// C1 controls are otherwise off. The payload never reaches the screen; it is
+// handed to the DCS handler, if any, so sixel and similar data cannot garble
+// the cell grid.
+func (te *TerminalEmulator) processDCSByte(b byte) {
+	if b == 0x1B || b == 0x9C {
+		if handler := te.dcsHandler; handler != nil && !te.parser.dcsOverflow {
+			data := append([]byte(nil), te.parser.buffer...)
+			te.pendingEvents = append(te.pendingEvents, func() { handler(data) })
+		}
+		te.parser.buffer = te.parser.buffer[:0]
+		te.parser.dcsOverflow = false
+		te.parser.state = StateNormal
+		if b == 0x1B {
+			// ESC \ (ST): consume the trailing byte as an escape sequence
+			te.parser.state = StateEscape
+		}
+		return
+	}
+	if len(te.parser.buffer) >= maxDCSLength {
+		te.parser.dcsOverflow = true
+		return
+	}
+	te.parser.buffer = append(te.parser.buffer, b)
+}
+
+// executeOSCCommand dispatches a complete OSC sequence; unsupported commands are ignored
+func (te *TerminalEmulator) executeOSCCommand(seq string) {
+	cmd, arg, _ := strings.Cut(seq, ";")
+	switch cmd {
+	case "0", "2": // Icon name and window title, window title
+		te.setTitle(arg)
+	case "8": // Hyperlink
+		te.handleHyperlinkOSC(arg)
+	case "52": // Clipboard
+		te.handleClipboardOSC(arg)
+	}
+}
+
+// setTitle records a new window title and queues the title handler
+func (te *TerminalEmulator) setTitle(title string) {
+	if title == te.title {
+		return
+	}
+	te.title = title
+
+	if handler := te.titleHandler; handler != nil {
+		te.pendingEvents = append(te.pendingEvents, func() { handler(title) })
+	}
+}
+
+// handleClipboardOSC parses an OSC 52 payload of the form "<selection>;<base64>"
+func (te *TerminalEmulator) handleClipboardOSC(arg string) {
+	selection, payload, ok := strings.Cut(arg, ";")
+	if te.clipboardHandler == nil || !ok || payload == "?" {
+		// No handler, malformed, or a clipboard read request which is never honoured
+		return
+	}
+
+	data, err := base64.StdEncoding.DecodeString(payload)
+	if err != nil {
+		return
 	}
-	// For now, ignore OSC sequences
+
+	if selection == "" {
+		selection = "c"
+	}
+	handler := te.clipboardHandler
+	te.pendingEvents = append(te.pendingEvents, func() { handler(selection, data) })
 }
 
 // Helper function eliminates redundant parameter extraction
@@ -239,9 +646,13 @@ func (te *TerminalEmulator) executeCSICommand(cmd byte) {
 		te.cursorX = max(0, te.cursorX-count)
 
 	case 'H', 'f': // Cursor Position - now with consistent bounds checking
-		row := te.getBoundedCSIParam(0, 1, 1, te.height)
+		top, rows := 0, te.height
+		if te.originMode {
+			top, rows = te.scrollTop, te.scrollBottom-te.scrollTop+1
+		}
+		row := te.getBoundedCSIParam(0, 1, 1, rows)
 		col := te.getBoundedCSIParam(1, 1, 1, te.width)
-		te.cursorY = row - 1
+		te.cursorY = top + row - 1
 		te.cursorX = col - 1
 
 	case 'J': // Erase in Display
@@ -266,9 +677,36 @@ func (te *TerminalEmulator) executeCSICommand(cmd byte) {
 			te.eraseEntireLine()
 		}
 
+	case 'S': // Scroll Up within the scrolling region
+		count := min(te.getCSIParam(0, 1), te.scrollBottom-te.scrollTop+1)
+		for i := 0; i < count; i++ {
+			te.scroll()
+		}
+
+	case 'T': // Scroll Down; the five-parameter form is mouse highlight tracking
+		if len(te.parser.params) > 1 {
+			break
+		}
+		count := min(te.getCSIParam(0, 1), te.scrollBottom-te.scrollTop+1)
+		for i := 0; i < count; i++ {
+			te.reverseScroll()
+		}
+
+	case 'h', 'l': // Set/Reset Mode
+		te.setModes(ansiModes, cmd == 'h')
+
 	case 'm': // Select Graphic Rendition
 		te.processGraphicRendition(te.parser.params)
 
+	case 's': // Save cursor (SCOSC), as DECSC
+		te.saveCursor()
+
+	case 'u': // Restore cursor (SCORC), as DECRC
+		te.restoreCursor()
+
+	case 't': // Window manipulation; only the size reports are answered
+		te.reportWindow(te.getCSIParam(0, 0))
+
 	case 'r': // Set Scrolling Region - now with proper validation
 		top := te.getBoundedCSIParam(0, 1, 1, te.height)
 		bottom := te.getBoundedCSIParam(1, te.height, top, te.height)
@@ -277,6 +715,20 @@ func (te *TerminalEmulator) executeCSICommand(cmd byte) {
 	}
 }
 
+// executeCSIIntermediateCommand executes CSI commands carrying intermediate bytes
+func (te *TerminalEmulator) executeCSIIntermediateCommand(cmd byte) {
+	switch string(te.parser.intermediates) + string(cmd) {
+	case " q": // DECSCUSR - Set cursor style
+		style := CursorStyle(te.getCSIParam(0, 0))
+		if style == CursorStyleDefault {
+			style = CursorStyleSteadyBlock
+		}
+		if style <= CursorStyleSteadyBar {
+			te.cursorStyle = style
+		}
+	}
+}
+
 // processGraphicRendition handles color and attribute changes
 func (te *TerminalEmulator) processGraphicRendition(params []int) {
 	if len(params) == 0 {
@@ -286,7 +738,7 @@ func (te *TerminalEmulator) processGraphicRendition(params []int) {
 	for _, param := range params {
 		switch param {
 		case 0: // Reset
-			te.currentAttr = CellAttributes{Foreground: Color{R: 255, G: 255, B: 255}}
+			te.currentAttr = defaultAttr
 		case 1: // Bold
 			te.currentAttr.Bold = true
 		case 4: // Underline
@@ -311,11 +763,20 @@ func (te *TerminalEmulator) processGraphicRendition(params []int) {
 
 // putChar places a character at the current cursor position
 func (te *TerminalEmulator) putChar(ch rune) {
+	if te.charsets[te.activeCharset] == CharsetDECSpecialGraphics {
+		if mapped, ok := decSpecialGraphics[ch]; ok {
+			ch = mapped
+		}
+	}
 	if te.cursorY >= 0 && te.cursorY < te.height && te.cursorX >= 0 && te.cursorX < te.width {
-		te.screen[te.cursorY][te.cursorX] = Cell{Char: ch, Attr: te.currentAttr}
+		te.setCell(te.cursorX, te.cursorY, Cell{Char: ch, Attr: te.currentAttr, Link: te.currentLink})
 		te.cursorX++
 		if te.cursorX >= te.width {
-			te.newline()
+			if te.autoWrap {
+				te.newline()
+			} else {
+				te.cursorX = te.width - 1
+			}
 		}
 	}
 }
@@ -330,6 +791,13 @@ func (te *TerminalEmulator) newline() {
 	}
 }
 
+// index moves the cursor down one line, keeping its column, scrolling if necessary
+func (te *TerminalEmulator) index() {
+	x := te.cursorX
+	te.newline()
+	te.cursorX = x
+}
+
 // reverseNewline moves up one line
 func (te *TerminalEmulator) reverseNewline() {
 	te.cursorY--
@@ -343,10 +811,11 @@ func (te *TerminalEmulator) reverseNewline() {
 func (te *TerminalEmulator) scroll() {
 	for y := te.scrollTop; y < te.scrollBottom; y++ {
 		copy(te.screen[y], te.screen[y+1])
+		te.markRowChanged(y)
 	}
 	// Clear the bottom line
 	for x := 0; x < te.width; x++ {
-		te.screen[te.scrollBottom][x] = Cell{Char: ' ', Attr: te.currentAttr}
+		te.setCell(x, te.scrollBottom, Cell{Char: ' ', Attr: te.currentAttr})
 	}
 }
 
@@ -354,10 +823,11 @@ func (te *TerminalEmulator) scroll() {
 func (te *TerminalEmulator) reverseScroll() {
 	for y := te.scrollBottom; y > te.scrollTop; y-- {
 		copy(te.screen[y], te.screen[y-1])
+		te.markRowChanged(y)
 	}
 	// Clear the top line
 	for x := 0; x < te.width; x++ {
-		te.screen[te.scrollTop][x] = Cell{Char: ' ', Attr: te.currentAttr}
+		te.setCell(x, te.scrollTop, Cell{Char: ' ', Attr: te.currentAttr})
 	}
 }
 
@@ -365,7 +835,7 @@ func (te *TerminalEmulator) reverseScroll() {
 func (te *TerminalEmulator) eraseScreen() {
 	for y := 0; y < te.height; y++ {
 		for x := 0; x < te.width; x++ {
-			te.screen[y][x] = Cell{Char: ' ', Attr: te.currentAttr}
+			te.setCell(x, y, Cell{Char: ' ', Attr: te.currentAttr})
 		}
 	}
 }
@@ -373,12 +843,12 @@ func (te *TerminalEmulator) eraseScreen() {
 func (te *TerminalEmulator) eraseFromCursorToEnd() {
 	// Clear from cursor to end of current line
 	for x := te.cursorX; x < te.width; x++ {
-		te.screen[te.cursorY][x] = Cell{Char: ' ', Attr: te.currentAttr}
+		te.setCell(x, te.cursorY, Cell{Char: ' ', Attr: te.currentAttr})
 	}
 	// Clear all lines below
 	for y := te.cursorY + 1; y < te.height; y++ {
 		for x := 0; x < te.width; x++ {
-			te.screen[y][x] = Cell{Char: ' ', Attr: te.currentAttr}
+			te.setCell(x, y, Cell{Char: ' ', Attr: te.currentAttr})
 		}
 	}
 }
@@ -387,30 +857,30 @@ func (te *TerminalEmulator) eraseFromStartToCursor() {
 	// Clear all lines above
 	for y := 0; y < te.cursorY; y++ {
 		for x := 0; x < te.width; x++ {
-			te.screen[y][x] = Cell{Char: ' ', Attr: te.currentAttr}
+			te.setCell(x, y, Cell{Char: ' ', Attr: te.currentAttr})
 		}
 	}
 	// Clear from start of current line to cursor
 	for x := 0; x <= te.cursorX; x++ {
-		te.screen[te.cursorY][x] = Cell{Char: ' ', Attr: te.currentAttr}
+		te.setCell(x, te.cursorY, Cell{Char: ' ', Attr: te.currentAttr})
 	}
 }
 
 func (te *TerminalEmulator) eraseEntireLine() {
 	for x := 0; x < te.width; x++ {
-		te.screen[te.cursorY][x] = Cell{Char: ' ', Attr: te.currentAttr}
+		te.setCell(x, te.cursorY, Cell{Char: ' ', Attr: te.currentAttr})
 	}
 }
 
 func (te *TerminalEmulator) eraseFromCursorToEndOfLine() {
 	for x := te.cursorX; x < te.width; x++ {
-		te.screen[te.cursorY][x] = Cell{Char: ' ', Attr: te.currentAttr}
+		te.setCell(x, te.cursorY, Cell{Char: ' ', Attr: te.currentAttr})
 	}
 }
 
 func (te *TerminalEmulator) eraseFromStartOfLineToCursor() {
 	for x := 0; x <= te.cursorX; x++ {
-		te.screen[te.cursorY][x] = Cell{Char: ' ', Attr: te.currentAttr}
+		te.setCell(x, te.cursorY, Cell{Char: ' ', Attr: te.currentAttr})
 	}
 }
 
@@ -420,21 +890,58 @@ func (te *TerminalEmulator) reset() {
 	te.cursorY = 0
 	te.scrollTop = 0
 	te.scrollBottom = te.height - 1
-	te.currentAttr = CellAttributes{Foreground: Color{R: 255, G: 255, B: 255}}
+	te.currentAttr = defaultAttr
+	te.cursorStyle = CursorStyleSteadyBlock
+	te.charsets = [2]Charset{}
+	te.activeCharset = 0
+	te.bracketedPaste = false
+	te.appCursorKeys = false
+	te.autoWrap = true
+	te.cursorHidden = false
+	te.newlineMode = false
+	te.originMode = false
+	te.currentLink = 0
+	te.savedCursors = nil
+	if te.mainScreen != nil {
+		te.screen = te.mainScreen
+		te.mainScreen = nil
+	}
 	te.eraseScreen()
+	// The screen no longer refers to any link
+	te.links.reset()
 }
 
 // GetScreen returns a copy of the current screen state
 func (te *TerminalEmulator) GetScreen() [][]Cell {
+	return te.CopyScreen(nil)
+}
+
+// CopyScreen copies the current screen state into dst and returns it,
+// allocating a new grid only when dst does not match the screen size.
+// Reusing the previous result avoids a full allocation on every frame.
+func (te *TerminalEmulator) CopyScreen(dst [][]Cell) [][]Cell {
 	te.mu.RLock()
 	defer te.mu.RUnlock()
 
-	screen := make([][]Cell, te.height)
-	for i := range screen {
-		screen[i] = make([]Cell, te.width)
-		copy(screen[i], te.screen[i])
+	if len(dst) != te.height || (te.height > 0 && len(dst[0]) != te.width) {
+		cells := make([]Cell, te.width*te.height)
+		dst = make([][]Cell, te.height)
+		for i := range dst {
+			dst[i] = cells[i*te.width : (i+1)*te.width : (i+1)*te.width]
+		}
+	}
+
+	for i := range dst {
+		copy(dst[i], te.screen[i])
 	}
-	return screen
+	return dst
+}
+
+// GetCursorStyle returns the cursor shape requested by the remote side
+func (te *TerminalEmulator) GetCursorStyle() CursorStyle {
+	te.mu.RLock()
+	defer te.mu.RUnlock()
+	return te.cursorStyle
 }
 
 // GetCursor returns the current cursor position
@@ -444,35 +951,94 @@ func (te *TerminalEmulator) GetCursor() (int, int) {
 	return te.cursorX, te.cursorY
 }
 
-// Resize changes the terminal dimensions
-func (te *TerminalEmulator) Resize(width, height int) {
+// BracketedPaste reports whether the remote side enabled bracketed paste
+// mode, in which pasted text should be wrapped in ESC [200~ and ESC [201~
+func (te *TerminalEmulator) BracketedPaste() bool {
+	te.mu.RLock()
+	defer te.mu.RUnlock()
+	return te.bracketedPaste
+}
+
+// NewlineMode reports whether newline mode (LNM) is set, in which LF also
+// returns the cursor to column 0 and Enter should be sent as CR LF
+func (te *TerminalEmulator) NewlineMode() bool {
+	te.mu.RLock()
+	defer te.mu.RUnlock()
+	return te.newlineMode
+}
+
+// SetNewlineMode sets newline mode (LNM) as the server would with CSI 20 h,
+// for servers that send bare LF line endings without enabling it
+func (te *TerminalEmulator) SetNewlineMode(enabled bool) {
 	te.mu.Lock()
 	defer te.mu.Unlock()
+	te.newlineMode = enabled
+}
 
-	// Create new screen buffer
-	newScreen := make([][]Cell, height)
-	for i := range newScreen {
-		newScreen[i] = make([]Cell, width)
-		for j := range newScreen[i] {
-			newScreen[i][j] = Cell{Char: ' ', Attr: te.currentAttr}
-		}
+// SetMaxSize sets the largest dimensions Resize accepts
+func (te *TerminalEmulator) SetMaxSize(width, height int) {
+	te.mu.Lock()
+	defer te.mu.Unlock()
+	te.maxWidth = width
+	te.maxHeight = height
+}
+
+// SetC1Controls enables interpretation of 8-bit C1 control bytes such as
+// 0x85 (NEL). It is off by default because those bytes also occur inside
+// UTF-8 encoded characters.
+func (te *TerminalEmulator) SetC1Controls(enabled bool) {
+	te.mu.Lock()
+	defer te.mu.Unlock()
+	te.c1Controls = enabled
+}
+
+// ValidateSize checks that width and height are positive and within the
+// given limits, returning a wrapped dgclient.ErrInvalidTerminalSize otherwise
+func ValidateSize(width, height, maxWidth, maxHeight int) error {
+	if width <= 0 || height <= 0 {
+		return fmt.Errorf("%w: %dx%d", dgclient.ErrInvalidTerminalSize, width, height)
+	}
+	if width > maxWidth || height > maxHeight {
+		return fmt.Errorf("%w: %dx%d exceeds maximum %dx%d",
+			dgclient.ErrInvalidTerminalSize, width, height, maxWidth, maxHeight)
 	}
+	return nil
+}
+
+// clampSize limits width and height to the sizes Resize accepts
+func (te *TerminalEmulator) clampSize(width, height int) (int, int) {
+	te.mu.RLock()
+	defer te.mu.RUnlock()
+	return max(1, min(width, te.maxWidth)), max(1, min(height, te.maxHeight))
+}
 
-	// Copy existing content
-	copyHeight := min(height, te.height)
-	copyWidth := min(width, te.width)
-	for y := 0; y < copyHeight; y++ {
-		copy(newScreen[y][:copyWidth], te.screen[y][:copyWidth])
+// Resize changes the terminal dimensions. Sizes that are not positive or
+// exceed the configured maximum are rejected and leave the screen unchanged.
+func (te *TerminalEmulator) Resize(width, height int) error {
+	te.mu.Lock()
+	defer te.mu.Unlock()
+
+	if err := ValidateSize(width, height, te.maxWidth, te.maxHeight); err != nil {
+		return err
 	}
 
-	te.screen = newScreen
+	te.screen = resizeGrid(te.screen, width, height, te.currentAttr)
+	if te.mainScreen != nil {
+		te.mainScreen = resizeGrid(te.mainScreen, width, height, te.currentAttr)
+	}
 	te.width = width
 	te.height = height
 	te.scrollBottom = height - 1
+	if te.changes != nil {
+		te.changes = newChangeSet(width, height)
+		te.changes.markAll()
+	}
 
 	// Adjust cursor position
 	te.cursorX = min(te.cursorX, width-1)
 	te.cursorY = min(te.cursorY, height-1)
+
+	return nil
 }
 
 // Helper functions
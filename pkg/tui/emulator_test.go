@@ -1,7 +1,13 @@
 package tui
 
 import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
 	"testing"
+
+	"github.com/opd-ai/go-gamelaunch-client/pkg/dgclient"
 )
 
 func TestNewTerminalEmulator(t *testing.T) {
@@ -54,7 +60,7 @@ func TestProcessDataSimpleText(t *testing.T) {
 func TestProcessDataNewline(t *testing.T) {
 	te := NewTerminalEmulator(80, 24)
 
-	te.ProcessData([]byte("Line1\nLine2"))
+	te.ProcessData([]byte("Line1\r\nLine2"))
 
 	screen := te.GetScreen()
 
@@ -154,3 +160,708 @@ func TestResize(t *testing.T) {
 		}
 	}
 }
+
+func TestProcessDataOSC52Clipboard(t *testing.T) {
+	te := NewTerminalEmulator(80, 24)
+
+	var gotSelection string
+	var gotData []byte
+	calls := 0
+	te.SetClipboardHandler(func(selection string, data []byte) {
+		calls++
+		gotSelection = selection
+		gotData = data
+	})
+
+	// "hello" base64-encoded, BEL terminated
+	te.ProcessData([]byte("\x1b]52;c;aGVsbG8=\x07"))
+
+	if calls != 1 {
+		t.Fatalf("Expected 1 clipboard call, got %d", calls)
+	}
+	if gotSelection != "c" {
+		t.Errorf("Expected selection 'c', got '%s'", gotSelection)
+	}
+	if string(gotData) != "hello" {
+		t.Errorf("Expected clipboard data 'hello', got '%s'", gotData)
+	}
+
+	// ST terminated sequence must not leave a stray '\' on screen
+	te.ProcessData([]byte("\x1b]52;p;d29ybGQ=\x1b\\"))
+	if calls != 2 || string(gotData) != "world" || gotSelection != "p" {
+		t.Errorf("Expected ST-terminated payload 'world' on 'p', got '%s' on '%s'", gotData, gotSelection)
+	}
+	if ch := te.GetScreen()[0][0].Char; ch != ' ' {
+		t.Errorf("Expected no output from OSC sequence, got '%c'", ch)
+	}
+
+	// Clipboard read requests are ignored
+	te.ProcessData([]byte("\x1b]52;c;?\x07"))
+	if calls != 2 {
+		t.Errorf("Clipboard read request should be ignored, got %d calls", calls)
+	}
+}
+
+func TestProcessDataOSCTitle(t *testing.T) {
+	te := NewTerminalEmulator(80, 24)
+
+	var titles []string
+	te.SetTitleHandler(func(title string) {
+		titles = append(titles, title)
+	})
+
+	te.ProcessData([]byte("\x1b]0;NetHack\x07"))
+	te.ProcessData([]byte("\x1b]2;NetHack\x07")) // unchanged, no callback
+	te.ProcessData([]byte("\x1b]2;Crawl\x1b\\"))
+
+	if te.GetTitle() != "Crawl" {
+		t.Errorf("Expected title 'Crawl', got '%s'", te.GetTitle())
+	}
+	if len(titles) != 2 || titles[0] != "NetHack" || titles[1] != "Crawl" {
+		t.Errorf("Expected title changes [NetHack Crawl], got %v", titles)
+	}
+}
+
+func TestProcessDataCursorStyle(t *testing.T) {
+	tests := []struct {
+		seq  string
+		want CursorStyle
+	}{
+		{"\x1b[0 q", CursorStyleSteadyBlock},
+		{"\x1b[ q", CursorStyleSteadyBlock},
+		{"\x1b[1 q", CursorStyleBlinkingBlock},
+		{"\x1b[2 q", CursorStyleSteadyBlock},
+		{"\x1b[3 q", CursorStyleBlinkingUnderline},
+		{"\x1b[4 q", CursorStyleSteadyUnderline},
+		{"\x1b[5 q", CursorStyleBlinkingBar},
+		{"\x1b[6 q", CursorStyleSteadyBar},
+	}
+
+	for _, tt := range tests {
+		te := NewTerminalEmulator(80, 24)
+		te.ProcessData([]byte("\x1b[4 q")) // start from a non-default style
+		te.ProcessData([]byte(tt.seq))
+
+		if got := te.GetCursorStyle(); got != tt.want {
+			t.Errorf("%q: expected cursor style %d, got %d", tt.seq, tt.want, got)
+		}
+		if ch := te.GetScreen()[0][0].Char; ch != ' ' {
+			t.Errorf("%q: sequence leaked '%c' onto the screen", tt.seq, ch)
+		}
+	}
+
+	// Out of range values are ignored
+	te := NewTerminalEmulator(80, 24)
+	te.ProcessData([]byte("\x1b[5 q\x1b[9 q"))
+	if got := te.GetCursorStyle(); got != CursorStyleBlinkingBar {
+		t.Errorf("Expected invalid style to be ignored, got %d", got)
+	}
+}
+
+func TestResizeRejectsInvalidSizes(t *testing.T) {
+	te := NewTerminalEmulator(80, 24)
+	te.SetMaxSize(200, 100)
+
+	tests := []struct {
+		width, height int
+	}{
+		{0, 24},
+		{80, 0},
+		{-1, -1},
+		{201, 24},
+		{80, 101},
+		{100000, 100000},
+	}
+
+	for _, tt := range tests {
+		err := te.Resize(tt.width, tt.height)
+		if !errors.Is(err, dgclient.ErrInvalidTerminalSize) {
+			t.Errorf("Resize(%d, %d): expected ErrInvalidTerminalSize, got %v", tt.width, tt.height, err)
+		}
+	}
+
+	if len(te.screen) != 24 || len(te.screen[0]) != 80 {
+		t.Errorf("Rejected resize must not change the screen, got %dx%d", len(te.screen[0]), len(te.screen))
+	}
+
+	if err := te.Resize(200, 100); err != nil {
+		t.Errorf("Resize to the maximum should succeed, got %v", err)
+	}
+}
+
+func TestProcessDataBoundsCSIParams(t *testing.T) {
+	te := NewTerminalEmulator(80, 24)
+
+	// Sparse parameters must not index past the parsed values
+	te.ProcessData([]byte("\x1b[1;;;5H"))
+
+	te.ProcessData([]byte("\x1b[" + strings.Repeat(";", 100000) + "1m"))
+	if cap(te.parser.params) > maxCSIParams*2 {
+		t.Errorf("Expected params capped near %d, got capacity %d", maxCSIParams, cap(te.parser.params))
+	}
+
+	te.ProcessData([]byte("\x1b[99999999999999999999999H"))
+	if te.cursorX < 0 || te.cursorY < 0 || te.cursorX >= 80 || te.cursorY >= 24 {
+		t.Errorf("Expected cursor within screen, got %d,%d", te.cursorX, te.cursorY)
+	}
+}
+
+func TestProcessDataDiscardsOversizedOSC(t *testing.T) {
+	te := NewTerminalEmulator(80, 24)
+	te.ProcessData([]byte("\x1b]2;" + strings.Repeat("x", maxOSCLength+10) + "\x07"))
+
+	if te.GetTitle() != "" {
+		t.Error("Expected oversized OSC title to be discarded")
+	}
+	if len(te.parser.buffer) != 0 {
+		t.Errorf("Expected OSC buffer to be reset, got %d bytes", len(te.parser.buffer))
+	}
+
+	te.ProcessData([]byte("\x1b]2;ok\x07"))
+	if te.GetTitle() != "ok" {
+		t.Errorf("Expected title %q after oversized OSC, got %q", "ok", te.GetTitle())
+	}
+}
+
+func FuzzProcessData(f *testing.F) {
+	f.Add([]byte("hello\r\nworld"))
+	f.Add([]byte("\x1b[1;31mred\x1b[0m"))
+	f.Add([]byte("\x1b[10;20H\x1b[2J\x1b[K"))
+	f.Add([]byte("\x1b]2;title\x07\x1b]52;c;aGk=\x1b\\"))
+	f.Add([]byte("\x1b[5 q\x1b[?25l\x1b[3;;;7r"))
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+		te := NewTerminalEmulator(80, 24)
+		te.ProcessData(data)
+
+		if len(te.parser.params) > maxCSIParams {
+			t.Errorf("params grew to %d", len(te.parser.params))
+		}
+		if len(te.parser.intermediates) > maxCSIIntermediates {
+			t.Errorf("intermediates grew to %d", len(te.parser.intermediates))
+		}
+		if len(te.parser.buffer) > maxOSCLength {
+			t.Errorf("OSC buffer grew to %d", len(te.parser.buffer))
+		}
+		if te.cursorX < 0 || te.cursorY < 0 || te.cursorX >= te.width || te.cursorY >= te.height {
+			t.Errorf("cursor %d,%d outside %dx%d screen", te.cursorX, te.cursorY, te.width, te.height)
+		}
+	})
+}
+
+// screenLines returns the first character of each screen row
+func screenLines(te *TerminalEmulator) string {
+	var sb strings.Builder
+	for _, row := range te.GetScreen() {
+		sb.WriteRune(row[0].Char)
+	}
+	return sb.String()
+}
+
+func TestProcessDataScrollUpDown(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  string
+	}{
+		{"scroll up", "\x1b[S", "BCDE "},
+		{"scroll up count", "\x1b[2S", "CDE  "},
+		{"scroll down", "\x1b[T", " ABCD"},
+		{"scroll up in region", "\x1b[2;4r\x1b[S", "ACD E"},
+		{"scroll down in region", "\x1b[2;4r\x1b[2T", "A  BE"},
+		{"count beyond region", "\x1b[2;4r\x1b[99S", "A   E"},
+		{"mouse tracking form ignored", "\x1b[1;2;3;4;5T", "ABCDE"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			te := NewTerminalEmulator(10, 5)
+			te.ProcessData([]byte("A\r\nB\r\nC\r\nD\r\nE"))
+			te.ProcessData([]byte(tt.input))
+
+			if got := screenLines(te); got != tt.want {
+				t.Errorf("Expected rows %q, got %q", tt.want, got)
+			}
+		})
+	}
+}
+
+// rowString returns the characters of screen row y
+func rowString(te *TerminalEmulator, y int) string {
+	var sb strings.Builder
+	for _, cell := range te.GetScreen()[y] {
+		sb.WriteRune(cell.Char)
+	}
+	return strings.TrimRight(sb.String(), " ")
+}
+
+func TestProcessDataDECSpecialGraphics(t *testing.T) {
+	te := NewTerminalEmulator(10, 4)
+
+	// Box drawn with G0 designated to special graphics
+	te.ProcessData([]byte("\x1b(0lqqk\r\nx  x\r\nmqqj\x1b(B\r\nlqqk"))
+
+	want := []string{"┌──┐", "│  │", "└──┘", "lqqk"}
+	for y, line := range want {
+		if got := rowString(te, y); got != line {
+			t.Errorf("Row %d: expected %q, got %q", y, line, got)
+		}
+	}
+}
+
+func TestProcessDataShiftOutShiftIn(t *testing.T) {
+	te := NewTerminalEmulator(10, 2)
+
+	// G1 holds special graphics; SO selects it, SI returns to G0
+	te.ProcessData([]byte("\x1b)0a\x0eqx\x0fq"))
+
+	if got := rowString(te, 0); got != "a─│q" {
+		t.Errorf("Expected %q, got %q", "a─│q", got)
+	}
+
+	te.ProcessData([]byte("\x1bc\x0eq"))
+	if got := rowString(te, 0); got != "q" {
+		t.Errorf("Expected reset to clear charsets, got %q", got)
+	}
+}
+
+func TestProcessDataLineMovementControls(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		c1    bool
+		wantX int
+		wantY int
+	}{
+		{"ESC E next line", "abc\x1bE", false, 0, 1},
+		{"ESC D index keeps column", "abc\x1bD", false, 3, 1},
+		{"ESC M reverse index", "\r\nabc\x1bM", false, 3, 0},
+		{"C1 NEL", "abc\x85", true, 0, 1},
+		{"C1 IND", "abc\x84", true, 3, 1},
+		{"C1 RI", "\r\nabc\x8d", true, 3, 0},
+		{"C1 ignored by default", "abc\x85", false, 4, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			te := NewTerminalEmulator(80, 24)
+			te.SetC1Controls(tt.c1)
+			te.ProcessData([]byte(tt.input))
+
+			if x, y := te.GetCursor(); x != tt.wantX || y != tt.wantY {
+				t.Errorf("Expected cursor at (%d,%d), got (%d,%d)", tt.wantX, tt.wantY, x, y)
+			}
+		})
+	}
+}
+
+func TestProcessDataUTF8(t *testing.T) {
+	te := NewTerminalEmulator(20, 2)
+
+	// "·" and "─" split across calls, then an invalid byte
+	te.ProcessData([]byte("a\xc2"))
+	te.ProcessData([]byte("\xb7b\xe2\x94"))
+	te.ProcessData([]byte("\x80\xffc"))
+
+	if got := rowString(te, 0); got != "a·b─�c" {
+		t.Errorf("Expected %q, got %q", "a·b─�c", got)
+	}
+	if x, _ := te.GetCursor(); x != 6 {
+		t.Errorf("Expected cursor after 6 characters, got column %d", x)
+	}
+}
+
+// loadFrames reads the sample NetHack session output used by benchmarks:
+// a full map draw followed by ~120 turns of cursor-addressed updates
+func loadFrames(b *testing.B) []byte {
+	b.Helper()
+	data, err := os.ReadFile("testdata/nethack.frames")
+	if err != nil {
+		b.Fatalf("Failed to read frames: %v", err)
+	}
+	return data
+}
+
+func BenchmarkProcessData(b *testing.B) {
+	frames := loadFrames(b)
+
+	for _, size := range []struct{ w, h int }{{80, 24}, {200, 60}} {
+		b.Run(fmt.Sprintf("%dx%d", size.w, size.h), func(b *testing.B) {
+			te := NewTerminalEmulator(size.w, size.h)
+			b.SetBytes(int64(len(frames)))
+			b.ReportAllocs()
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				te.ProcessData(frames)
+			}
+		})
+	}
+}
+
+func BenchmarkProcessDataSmallReads(b *testing.B) {
+	frames := loadFrames(b)
+	te := NewTerminalEmulator(80, 24)
+
+	b.SetBytes(int64(len(frames)))
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		for off := 0; off < len(frames); off += 64 {
+			te.ProcessData(frames[off:min(off+64, len(frames))])
+		}
+	}
+}
+
+func TestCopyScreenReusesBuffer(t *testing.T) {
+	te := NewTerminalEmulator(10, 3)
+	te.ProcessData([]byte("abc"))
+
+	first := te.CopyScreen(nil)
+	te.ProcessData([]byte("d"))
+	second := te.CopyScreen(first)
+
+	if &second[0][0] != &first[0][0] {
+		t.Error("Expected CopyScreen to reuse a buffer of matching size")
+	}
+	if second[0][3].Char != 'd' {
+		t.Errorf("Expected copied screen to be current, got %q", second[0][3].Char)
+	}
+
+	te.Resize(20, 3)
+	if resized := te.CopyScreen(second); len(resized[0]) != 20 {
+		t.Errorf("Expected a new 20-column grid after resize, got %d columns", len(resized[0]))
+	}
+}
+
+func TestGetChangesReportsWrittenCells(t *testing.T) {
+	te := NewTerminalEmulator(10, 3)
+	if changes := te.GetChanges(); changes != nil {
+		t.Errorf("Expected no changes with tracking disabled, got %v", changes)
+	}
+
+	te.SetChangeTracking(true)
+	te.ProcessData([]byte("\x1b[2;3Hhi"))
+
+	changes := te.GetChanges()
+	if len(changes) != 2 {
+		t.Fatalf("Expected 2 changes, got %d", len(changes))
+	}
+	if c := changes[0]; c.X != 2 || c.Y != 1 || c.Cell.Char != 'h' {
+		t.Errorf("Expected 'h' at (2,1), got %q at (%d,%d)", c.Cell.Char, c.X, c.Y)
+	}
+	if c := changes[1]; c.X != 3 || c.Y != 1 || c.Cell.Char != 'i' {
+		t.Errorf("Expected 'i' at (3,1), got %q at (%d,%d)", c.Cell.Char, c.X, c.Y)
+	}
+
+	if changes := te.GetChanges(); changes != nil {
+		t.Errorf("Expected change set to be cleared, got %v", changes)
+	}
+}
+
+func TestGetChangesAfterScrollAndResize(t *testing.T) {
+	te := NewTerminalEmulator(4, 2)
+	te.SetChangeTracking(true)
+
+	te.ProcessData([]byte("\x1b[2;1H\n"))
+	if changes := te.GetChanges(); len(changes) != 8 {
+		t.Errorf("Expected scroll to change every cell, got %d", len(changes))
+	}
+
+	te.Resize(5, 3)
+	if changes := te.GetChanges(); len(changes) != 15 {
+		t.Errorf("Expected resize to change every cell, got %d", len(changes))
+	}
+}
+
+// BenchmarkFrameUpdate compares pulling a full snapshot after each frame
+// with applying only the changed cells
+func BenchmarkFrameUpdate(b *testing.B) {
+	frames := loadFrames(b)
+	frame := []byte("\x1b[12;40H@\x1b[12;39H.\x1b[24;1HDlvl:1 $:0 HP:12(12) Pw:7(7) AC:7 T:42")
+
+	b.Run("snapshot", func(b *testing.B) {
+		te := NewTerminalEmulator(200, 60)
+		te.ProcessData(frames)
+		grid := te.CopyScreen(nil)
+		b.ReportAllocs()
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			te.ProcessData(frame)
+			grid = te.CopyScreen(grid)
+		}
+	})
+
+	b.Run("changes", func(b *testing.B) {
+		te := NewTerminalEmulator(200, 60)
+		te.ProcessData(frames)
+		grid := te.CopyScreen(nil)
+		te.SetChangeTracking(true)
+		b.ReportAllocs()
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			te.ProcessData(frame)
+			for _, c := range te.GetChanges() {
+				grid[c.Y][c.X] = c.Cell
+			}
+		}
+	})
+}
+
+func TestIgnoredControlBytes(t *testing.T) {
+	te := NewTerminalEmulator(10, 3)
+	te.ProcessData([]byte("a\x00b\x7fc\x05\x1ad\x00\x00"))
+
+	if got := rowString(te, 0); got != "abcd" {
+		t.Errorf("Expected control bytes to be ignored, got %q", got)
+	}
+	if x, _ := te.GetCursor(); x != 4 {
+		t.Errorf("Expected cursor at column 4, got %d", x)
+	}
+}
+
+func TestVerticalTabAndFormFeedMoveDown(t *testing.T) {
+	te := NewTerminalEmulator(10, 3)
+	te.ProcessData([]byte("ab\vc\fd"))
+
+	// Like LF, they keep the column unless newline mode is set
+	for y, want := range []string{"ab", "  c", "   d"} {
+		if got := rowString(te, y); got != want {
+			t.Errorf("Expected row %d to be %q, got %q", y, want, got)
+		}
+	}
+
+	te = NewTerminalEmulator(10, 3)
+	te.SetNewlineMode(true)
+	te.ProcessData([]byte("ab\vc\fd"))
+	for y, want := range []string{"ab", "c", "d"} {
+		if got := rowString(te, y); got != want {
+			t.Errorf("Newline mode: expected row %d to be %q, got %q", y, want, got)
+		}
+	}
+}
+
+const testSixel = "\x1bPq#0;2;0;0;0#1;2;100;100;0#1~~@@vv@@~~@@~~$#2??}}GG}}??}}??-#1!14@\x1b\\"
+
+func TestSixelDoesNotGarbleScreen(t *testing.T) {
+	te := NewTerminalEmulator(20, 3)
+	te.ProcessData([]byte("before" + testSixel + "after"))
+
+	if got := rowString(te, 0); got != "beforeafter" {
+		t.Errorf("Expected sixel data to be skipped, got %q", got)
+	}
+}
+
+func TestDCSHandlerReceivesPayload(t *testing.T) {
+	te := NewTerminalEmulator(20, 3)
+	var got []byte
+	te.SetDCSHandler(func(data []byte) { got = data })
+
+	// Split across calls to check the parser keeps DCS state
+	te.ProcessData([]byte(testSixel[:10]))
+	te.ProcessData([]byte(testSixel[10:] + "x"))
+
+	want := testSixel[2 : len(testSixel)-2]
+	if string(got) != want {
+		t.Errorf("Expected payload %q, got %q", want, got)
+	}
+	if row := rowString(te, 0); row != "x" {
+		t.Errorf("Expected text after the sequence to render, got %q", row)
+	}
+}
+
+func TestDCSEightBitTerminator(t *testing.T) {
+	te := NewTerminalEmulator(20, 3)
+	var got []byte
+	te.SetDCSHandler(func(data []byte) { got = data })
+
+	te.ProcessData([]byte("a\x1bP$qm\x9cb"))
+
+	if string(got) != "$qm" {
+		t.Errorf("Expected payload %q, got %q", "$qm", got)
+	}
+	if row := rowString(te, 0); row != "ab" {
+		t.Errorf("Expected text around the sequence to render, got %q", row)
+	}
+}
+
+func TestBracketedPasteMode(t *testing.T) {
+	te := NewTerminalEmulator(10, 2)
+	te.ProcessData([]byte("\x1b[?1;2004h"))
+	if !te.BracketedPaste() {
+		t.Error("Expected bracketed paste enabled")
+	}
+	te.ProcessData([]byte("\x1b[?2004l"))
+	if te.BracketedPaste() {
+		t.Error("Expected bracketed paste disabled")
+	}
+	te.ProcessData([]byte("\x1b[2004h"))
+	if te.BracketedPaste() {
+		t.Error("Expected non-private mode 2004 to be ignored")
+	}
+}
+
+func TestPrivateModes(t *testing.T) {
+	te := NewTerminalEmulator(10, 2)
+	if !te.CursorVisible() || te.ApplicationCursorKeys() || te.AltScreen() {
+		t.Fatal("Expected default modes")
+	}
+
+	te.ProcessData([]byte("\x1b[?1;1049h\x1b[?25l"))
+	if !te.ApplicationCursorKeys() {
+		t.Error("Expected application cursor keys enabled")
+	}
+	if te.CursorVisible() {
+		t.Error("Expected cursor hidden")
+	}
+	if !te.AltScreen() {
+		t.Error("Expected alternate screen active")
+	}
+
+	te.ProcessData([]byte("\x1b[?1;1049l\x1b[?25h"))
+	if te.ApplicationCursorKeys() || !te.CursorVisible() || te.AltScreen() {
+		t.Error("Expected modes reset")
+	}
+
+	te.ProcessData([]byte("\x1b[?1;1049h\x1b[?25l\x1bc"))
+	if te.ApplicationCursorKeys() || !te.CursorVisible() || te.AltScreen() {
+		t.Error("Expected RIS to restore default modes")
+	}
+}
+
+func TestAltScreenResize(t *testing.T) {
+	te := NewTerminalEmulator(10, 2)
+	te.ProcessData([]byte("main\x1b[?1049h"))
+	te.Resize(12, 3)
+	te.ProcessData([]byte("\x1b[?1049l"))
+	if row := rowString(te, 0); row != "main" {
+		t.Errorf("Expected main screen kept across resize, got %q", row)
+	}
+	if got := len(te.GetScreen()); got != 3 {
+		t.Errorf("Expected 3 rows after resize, got %d", got)
+	}
+}
+
+func TestSetNewlineMode(t *testing.T) {
+	te := NewTerminalEmulator(10, 3)
+	if te.NewlineMode() {
+		t.Fatal("Expected newline mode reset by default")
+	}
+	te.SetNewlineMode(true)
+	te.ProcessData([]byte("ab\ncd"))
+	if row := rowString(te, 1); row != "cd" {
+		t.Errorf("Expected LF to return to column 0, got %q", row)
+	}
+	te.ProcessData([]byte("\x1bc"))
+	if te.NewlineMode() {
+		t.Error("Expected RIS to reset newline mode")
+	}
+}
+
+func TestResetClearsHyperlinks(t *testing.T) {
+	te := NewTerminalEmulator(20, 2)
+	for i := 0; i < maxLinks; i++ {
+		te.ProcessData([]byte(fmt.Sprintf("\x1b]8;;https://example.com/%d\x07x", i)))
+	}
+	te.ProcessData([]byte("\x1bc\x1b]8;;https://nethackwiki.com\x07a"))
+
+	id := te.GetScreen()[0][0].Link
+	if id != 1 || te.LinkURL(id) != "https://nethackwiki.com" {
+		t.Errorf("Expected RIS to free link IDs, got ID %d for %q", id, te.LinkURL(id))
+	}
+}
+
+func TestHyperlinksReuseFreedIDs(t *testing.T) {
+	te := NewTerminalEmulator(120, 2)
+	te.ProcessData([]byte("\x1b]8;;https://kept.example\x07k\x1b]8;;\x07\r\n"))
+	for i := 0; i < 2*maxLinks; i++ {
+		if i%100 == 0 {
+			// Clear the bottom row, dropping the links written there
+			te.ProcessData([]byte("\x1b[2;1H\x1b[2K"))
+		}
+		te.ProcessData([]byte(fmt.Sprintf("\x1b]8;;https://example.com/%d\x07x\x1b]8;;\x07", i)))
+	}
+
+	screen := te.GetScreen()
+	if url := te.LinkURL(screen[0][0].Link); url != "https://kept.example" {
+		t.Errorf("Expected the link still on screen to keep its URL, got %q", url)
+	}
+	want := fmt.Sprintf("https://example.com/%d", 2*maxLinks-1)
+	if url := te.LinkURL(screen[1][(2*maxLinks-1)%100].Link); url != want {
+		t.Errorf("Expected links past maxLinks to still be linked, got %q", url)
+	}
+}
+
+func TestHyperlinks(t *testing.T) {
+	te := NewTerminalEmulator(20, 2)
+	te.ProcessData([]byte("a\x1b]8;;https://nethackwiki.com\x07wi\x1b[0mki\x1b]8;;\x07b"))
+
+	screen := te.GetScreen()
+	if screen[0][0].Link != 0 || screen[0][5].Link != 0 {
+		t.Error("Expected cells outside the link to carry no link")
+	}
+	for x := 1; x <= 4; x++ {
+		if got := te.LinkURL(screen[0][x].Link); got != "https://nethackwiki.com" {
+			t.Errorf("Cell %d: expected link URL, got %q", x, got)
+		}
+	}
+
+	// The same URL reuses its ID
+	te.ProcessData([]byte("\x1b]8;id=x;https://nethackwiki.com\x1b\\c\x1b]8;;\x1b\\"))
+	if screen := te.GetScreen(); screen[0][6].Link != screen[0][1].Link {
+		t.Errorf("Expected repeated URL to reuse link %d, got %d", screen[0][1].Link, screen[0][6].Link)
+	}
+
+	if got := te.LinkURL(99); got != "" {
+		t.Errorf("Expected unknown link ID to have no URL, got %q", got)
+	}
+}
+
+func TestWindowSizeReports(t *testing.T) {
+	tests := []struct {
+		name  string
+		query string
+		want  string
+	}{
+		{"char size", "\x1b[18t", "\x1b[8;24;80t"},
+		{"screen size", "\x1b[19t", "\x1b[9;24;80t"},
+		{"pixel size", "\x1b[14t", "\x1b[4;384;640t"},
+		{"resize ignored", "\x1b[8;50;100t", ""},
+		{"move ignored", "\x1b[3;0;0t", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			te := NewTerminalEmulator(80, 24)
+			var got []byte
+			te.SetResponseHandler(func(data []byte) { got = append(got, data...) })
+
+			te.ProcessData([]byte(tt.query))
+
+			if string(got) != tt.want {
+				t.Errorf("Expected response %q, got %q", tt.want, got)
+			}
+			if w, h := te.width, te.height; w != 80 || h != 24 {
+				t.Errorf("Expected size to stay 80x24, got %dx%d", w, h)
+			}
+		})
+	}
+}
+
+func TestSavedCursorStackDepth(t *testing.T) {
+	te := NewTerminalEmulator(40, 2)
+	for x := 0; x <= maxSavedCursors; x++ {
+		te.ProcessData([]byte(fmt.Sprintf("\x1b[1;%dH\x1b7", x+1)))
+	}
+
+	// The oldest save (column 0) was discarded; unwinding stops at column 1
+	for want := maxSavedCursors; want >= 1; want-- {
+		te.ProcessData([]byte("\x1b8"))
+		if x, _ := te.GetCursor(); x != want {
+			t.Fatalf("Expected restore to column %d, got %d", want, x)
+		}
+	}
+	te.ProcessData([]byte("\x1b8"))
+	if x, _ := te.GetCursor(); x != 1 {
+		t.Errorf("Expected the last save to be kept, got column %d", x)
+	}
+}
@@ -0,0 +1,132 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// StateExtractor derives semantic fields (status lines, message windows, ...)
+// from the emulator screen. It is called after each render with a snapshot
+// of the screen and must not retain it.
+type StateExtractor interface {
+	Extract(screen [][]Cell) map[string]any
+}
+
+// StateExtractorFunc is an adapter to allow functions to be used as StateExtractor
+type StateExtractorFunc func(screen [][]Cell) map[string]any
+
+func (f StateExtractorFunc) Extract(screen [][]Cell) map[string]any {
+	return f(screen)
+}
+
+// Region describes a rectangular area of the screen to extract as text
+type Region struct {
+	Name   string `yaml:"name"`
+	X      int    `yaml:"x"`
+	Y      int    `yaml:"y"`
+	Width  int    `yaml:"width"`
+	Height int    `yaml:"height,omitempty"`
+
+	// Raw keeps trailing whitespace on each extracted line
+	Raw bool `yaml:"raw,omitempty"`
+}
+
+// RegionExtractor extracts the text of configured screen regions by name.
+// A region spanning one line yields a string, taller regions a []string.
+type RegionExtractor struct {
+	Regions []Region `yaml:"regions"`
+}
+
+// LoadRegionExtractor parses a YAML region definition, for example:
+//
+//	regions:
+//	  - {name: message, x: 0, y: 0, width: 80}
+//	  - {name: status, x: 0, y: 22, width: 80, height: 2}
+func LoadRegionExtractor(data []byte) (*RegionExtractor, error) {
+	var extractor RegionExtractor
+	if err := yaml.Unmarshal(data, &extractor); err != nil {
+		return nil, fmt.Errorf("failed to parse region extractor: %w", err)
+	}
+
+	for i, region := range extractor.Regions {
+		if region.Name == "" {
+			return nil, fmt.Errorf("region %d has no name", i)
+		}
+		if region.X < 0 || region.Y < 0 || region.Width <= 0 || region.Height < 0 {
+			return nil, fmt.Errorf("region '%s' has invalid bounds", region.Name)
+		}
+	}
+
+	return &extractor, nil
+}
+
+// Extract implements StateExtractor
+func (e *RegionExtractor) Extract(screen [][]Cell) map[string]any {
+	fields := make(map[string]any, len(e.Regions))
+
+	for _, region := range e.Regions {
+		height := region.Height
+		if height <= 0 {
+			height = 1
+		}
+
+		lines := make([]string, 0, height)
+		for y := region.Y; y < region.Y+height; y++ {
+			lines = append(lines, regionLine(screen, y, region.X, region.Width, region.Raw))
+		}
+
+		if height == 1 {
+			fields[region.Name] = lines[0]
+		} else {
+			fields[region.Name] = lines
+		}
+	}
+
+	return fields
+}
+
+// GetText returns the screen as text, one line per row, with trailing spaces
+// trimmed from each line
+func (te *TerminalEmulator) GetText() string {
+	return screenText(te.GetScreen(), false)
+}
+
+// GetTextRaw returns the screen as text with every line at full width. Use it
+// where trailing blanks matter, for example a status bar whose background
+// color runs to the edge of the screen.
+func (te *TerminalEmulator) GetTextRaw() string {
+	return screenText(te.GetScreen(), true)
+}
+
+// screenText joins the rows of screen with newlines
+func screenText(screen [][]Cell, raw bool) string {
+	lines := make([]string, len(screen))
+	for y, row := range screen {
+		lines[y] = regionLine(screen, y, 0, len(row), raw)
+	}
+	return strings.Join(lines, "\n")
+}
+
+// regionLine returns the text of screen row y in columns [x, x+width), clipped to the screen
+func regionLine(screen [][]Cell, y, x, width int, raw bool) string {
+	if y < 0 || y >= len(screen) {
+		return ""
+	}
+
+	row := screen[y]
+	var sb strings.Builder
+	for col := x; col < x+width && col < len(row); col++ {
+		ch := row[col].Char
+		if ch == 0 {
+			ch = ' '
+		}
+		sb.WriteRune(ch)
+	}
+
+	if raw {
+		return sb.String()
+	}
+	return strings.TrimRight(sb.String(), " ")
+}
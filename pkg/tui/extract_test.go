@@ -0,0 +1,68 @@
+package tui
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestRegionExtractor(t *testing.T) {
+	config := []byte(`
+regions:
+  - {name: message, x: 0, y: 0, width: 20}
+  - {name: status, x: 0, y: 2, width: 10, height: 2}
+`)
+
+	extractor, err := LoadRegionExtractor(config)
+	if err != nil {
+		t.Fatalf("LoadRegionExtractor() failed: %v", err)
+	}
+
+	te := NewTerminalEmulator(20, 4)
+	te.ProcessData([]byte("You see a newt.\r\n\r\nDlvl:1 $:0\r\nHP:12(12)"))
+
+	fields := extractor.Extract(te.GetScreen())
+
+	if got := fields["message"]; got != "You see a newt." {
+		t.Errorf("Expected message 'You see a newt.', got %q", got)
+	}
+
+	want := []string{"Dlvl:1 $:0", "HP:12(12)"}
+	if got := fields["status"]; !reflect.DeepEqual(got, want) {
+		t.Errorf("Expected status %q, got %q", want, got)
+	}
+}
+
+func TestLoadRegionExtractorInvalid(t *testing.T) {
+	tests := []string{
+		`regions: [{x: 0, y: 0, width: 10}]`,
+		`regions: [{name: bad, x: -1, y: 0, width: 10}]`,
+		`regions: [{name: bad, x: 0, y: 0, width: 0}]`,
+		`regions: {`,
+	}
+
+	for _, input := range tests {
+		if _, err := LoadRegionExtractor([]byte(input)); err == nil {
+			t.Errorf("LoadRegionExtractor(%q) should fail", input)
+		}
+	}
+}
+
+func TestGetText(t *testing.T) {
+	te := NewTerminalEmulator(8, 3)
+	te.ProcessData([]byte("HP:12\r\n\x1b[41m Dlvl:1 \x1b[0m"))
+
+	tests := []struct {
+		name string
+		got  string
+		want string
+	}{
+		{"trimmed", te.GetText(), "HP:12\n Dlvl:1\n"},
+		{"raw", te.GetTextRaw(), "HP:12   \n Dlvl:1 \n        "},
+	}
+
+	for _, tt := range tests {
+		if tt.got != tt.want {
+			t.Errorf("%s: Expected %q, got %q", tt.name, tt.want, tt.got)
+		}
+	}
+}
@@ -0,0 +1,44 @@
+package tui
+
+import (
+	"fmt"
+	"unicode/utf8"
+)
+
+// ConfigGlyphMap is the ViewOptions.Config key holding a map[rune]rune of
+// character substitutions applied when drawing, e.g. '·' to '.' for fonts
+// lacking the glyph. Unmapped characters are drawn unchanged.
+const ConfigGlyphMap = "glyph_map"
+
+// SetGlyphMap replaces the character substitutions applied when drawing
+func (v *TerminalView) SetGlyphMap(glyphs map[rune]rune) {
+	v.drawMu.Lock()
+	v.glyphMap = glyphs
+	v.lastGrid = nil
+	v.drawMu.Unlock()
+
+	v.requestDraw()
+}
+
+// glyph returns the rune to draw for ch. Must be called with v.drawMu held.
+func (v *TerminalView) glyph(ch rune) rune {
+	if mapped, ok := v.glyphMap[ch]; ok {
+		return mapped
+	}
+	return ch
+}
+
+// ParseGlyphMap converts string pairs, as read from a config file, into a
+// glyph map. Each key and value must be a single character.
+func ParseGlyphMap(pairs map[string]string) (map[rune]rune, error) {
+	glyphs := make(map[rune]rune, len(pairs))
+	for from, to := range pairs {
+		if utf8.RuneCountInString(from) != 1 || utf8.RuneCountInString(to) != 1 {
+			return nil, fmt.Errorf("invalid glyph mapping %q: %q, expected single characters", from, to)
+		}
+		fromRune, _ := utf8.DecodeRuneInString(from)
+		toRune, _ := utf8.DecodeRuneInString(to)
+		glyphs[fromRune] = toRune
+	}
+	return glyphs, nil
+}
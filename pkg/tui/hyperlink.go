@@ -0,0 +1,110 @@
+package tui
+
+import "strings"
+
+// maxLinks caps the hyperlink table. When it fills up, IDs no longer used
+// by any cell are freed; links opened while every ID is on screen are
+// rendered as plain text.
+const maxLinks = 4096
+
+// linkTable interns OSC 8 hyperlink URLs so cells can refer to them by a
+// small ID. IDs start at 1; 0 means no link.
+type linkTable struct {
+	urls []string // by ID - 1; "" for a freed ID
+	ids  map[string]uint32
+	free []uint32
+}
+
+// intern returns the ID for url, adding it if needed, or 0 when the table
+// is full
+func (lt *linkTable) intern(url string) uint32 {
+	if id, ok := lt.ids[url]; ok {
+		return id
+	}
+	if lt.ids == nil {
+		lt.ids = make(map[string]uint32)
+	}
+
+	var id uint32
+	switch {
+	case len(lt.free) > 0:
+		id = lt.free[len(lt.free)-1]
+		lt.free = lt.free[:len(lt.free)-1]
+		lt.urls[id-1] = url
+	case len(lt.urls) < maxLinks:
+		lt.urls = append(lt.urls, url)
+		id = uint32(len(lt.urls))
+	default:
+		return 0
+	}
+	lt.ids[url] = id
+	return id
+}
+
+// sweep frees every ID for which inUse returns false
+func (lt *linkTable) sweep(inUse func(id uint32) bool) {
+	for i, url := range lt.urls {
+		id := uint32(i + 1)
+		if url == "" || inUse(id) {
+			continue
+		}
+		delete(lt.ids, url)
+		lt.urls[i] = ""
+		lt.free = append(lt.free, id)
+	}
+}
+
+// reset empties the table, freeing its IDs for reuse. No cell may still
+// refer to them.
+func (lt *linkTable) reset() {
+	lt.urls = nil
+	lt.ids = nil
+	lt.free = nil
+}
+
+// url returns the URL for id, or "" if id is unknown
+func (lt *linkTable) url(id uint32) string {
+	if id == 0 || int(id) > len(lt.urls) {
+		return ""
+	}
+	return lt.urls[id-1]
+}
+
+// handleHyperlinkOSC parses an OSC 8 payload of the form "<params>;<uri>".
+// A non-empty URI starts a link applied to the cells written after it, an
+// empty one ends it. The params (such as id=) are not used.
+func (te *TerminalEmulator) handleHyperlinkOSC(arg string) {
+	_, uri, ok := strings.Cut(arg, ";")
+	if !ok || uri == "" {
+		te.currentLink = 0
+		return
+	}
+	te.currentLink = te.links.intern(uri)
+	if te.currentLink == 0 {
+		te.links.sweep(te.linksInUse())
+		te.currentLink = te.links.intern(uri)
+	}
+}
+
+// linksInUse returns a lookup of the link IDs held by cells on either
+// screen
+func (te *TerminalEmulator) linksInUse() func(id uint32) bool {
+	used := make(map[uint32]bool)
+	for _, grid := range [][][]Cell{te.screen, te.mainScreen} {
+		for _, row := range grid {
+			for _, cell := range row {
+				if cell.Link != 0 {
+					used[cell.Link] = true
+				}
+			}
+		}
+	}
+	return func(id uint32) bool { return used[id] }
+}
+
+// LinkURL returns the hyperlink URL for a Cell.Link ID, or "" if there is none
+func (te *TerminalEmulator) LinkURL(id uint32) string {
+	te.mu.RLock()
+	defer te.mu.RUnlock()
+	return te.links.url(id)
+}
@@ -0,0 +1,123 @@
+package tui
+
+// privateModes maps DEC private mode numbers, set with CSI ? Pm h and reset
+// with CSI ? Pm l, to their handlers. Modes not listed are consumed and
+// ignored, so adding support for a mode only needs an entry here.
+var privateModes = map[int]func(te *TerminalEmulator, set bool){
+	1:    func(te *TerminalEmulator, set bool) { te.appCursorKeys = set }, // DECCKM
+	6:    (*TerminalEmulator).setOriginMode,                               // DECOM
+	7:    func(te *TerminalEmulator, set bool) { te.autoWrap = set },      // DECAWM
+	25:   func(te *TerminalEmulator, set bool) { te.cursorHidden = !set }, // DECTCEM
+	47:   func(te *TerminalEmulator, set bool) { te.setAltScreen(set, false) },
+	1047: func(te *TerminalEmulator, set bool) { te.setAltScreen(set, false) },
+	1049: func(te *TerminalEmulator, set bool) { te.setAltScreen(set, true) },
+	2004: func(te *TerminalEmulator, set bool) { te.bracketedPaste = set },
+}
+
+// ansiModes maps ANSI mode numbers, set with CSI Pm h and reset with
+// CSI Pm l, to their handlers
+var ansiModes = map[int]func(te *TerminalEmulator, set bool){
+	20: func(te *TerminalEmulator, set bool) { te.newlineMode = set }, // LNM
+}
+
+// executePrivateCSICommand handles CSI sequences with a private parameter
+// marker, dispatching DECSET/DECRST through privateModes. Other private
+// sequences are consumed and ignored.
+func (te *TerminalEmulator) executePrivateCSICommand(cmd byte) {
+	if te.parser.private != '?' || (cmd != 'h' && cmd != 'l') {
+		return
+	}
+	te.setModes(privateModes, cmd == 'h')
+}
+
+// setModes sets or resets each mode in the CSI parameters, ignoring modes
+// missing from the table
+func (te *TerminalEmulator) setModes(modes map[int]func(te *TerminalEmulator, set bool), set bool) {
+	for _, mode := range te.parser.params {
+		if handler, ok := modes[mode]; ok {
+			handler(te, set)
+		}
+	}
+}
+
+// setAltScreen switches to or from the alternate screen. With saveCursor
+// (mode 1049) the cursor is saved on entry and restored on exit, and the
+// alternate screen starts blank.
+func (te *TerminalEmulator) setAltScreen(enter, saveCursor bool) {
+	if enter == (te.mainScreen != nil) {
+		return
+	}
+
+	if enter {
+		if saveCursor {
+			te.saveCursor()
+		}
+		te.mainScreen = te.screen
+		te.screen = newGrid(te.width, te.height, te.currentAttr)
+	} else {
+		te.screen = te.mainScreen
+		te.mainScreen = nil
+		if saveCursor {
+			te.restoreCursor()
+		}
+	}
+
+	if te.changes != nil {
+		te.changes.markAll()
+	}
+}
+
+// setOriginMode sets or resets DECOM and homes the cursor, to the top of
+// the scrolling region when set
+func (te *TerminalEmulator) setOriginMode(set bool) {
+	te.originMode = set
+	te.cursorX = 0
+	te.cursorY = 0
+	if set {
+		te.cursorY = te.scrollTop
+	}
+}
+
+// newGrid returns a blank width x height cell grid
+func newGrid(width, height int, attr CellAttributes) [][]Cell {
+	grid := make([][]Cell, height)
+	for y := range grid {
+		grid[y] = make([]Cell, width)
+		for x := range grid[y] {
+			grid[y][x] = Cell{Char: ' ', Attr: attr}
+		}
+	}
+	return grid
+}
+
+// resizeGrid returns a width x height copy of grid, keeping the top-left
+// content that still fits
+func resizeGrid(grid [][]Cell, width, height int, attr CellAttributes) [][]Cell {
+	resized := newGrid(width, height, attr)
+	for y := 0; y < min(height, len(grid)); y++ {
+		copy(resized[y], grid[y])
+	}
+	return resized
+}
+
+// ApplicationCursorKeys reports whether the remote side enabled application
+// cursor keys (DECCKM), in which arrows are sent as ESC O A rather than ESC [ A
+func (te *TerminalEmulator) ApplicationCursorKeys() bool {
+	te.mu.RLock()
+	defer te.mu.RUnlock()
+	return te.appCursorKeys
+}
+
+// CursorVisible reports whether the remote side wants the cursor shown (DECTCEM)
+func (te *TerminalEmulator) CursorVisible() bool {
+	te.mu.RLock()
+	defer te.mu.RUnlock()
+	return !te.cursorHidden
+}
+
+// AltScreen reports whether the alternate screen is active
+func (te *TerminalEmulator) AltScreen() bool {
+	te.mu.RLock()
+	defer te.mu.RUnlock()
+	return te.mainScreen != nil
+}
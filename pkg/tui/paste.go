@@ -0,0 +1,67 @@
+package tui
+
+import "unicode/utf8"
+
+// pasteChunkSize bounds each input message a paste is split into
+const pasteChunkSize = 1024
+
+// Bracketed paste markers sent around pasted text when the remote side
+// enabled bracketed paste mode
+const (
+	pasteStartSeq = "\x1b[200~"
+	pasteEndSeq   = "\x1b[201~"
+)
+
+// Paste queues text as pasted input and returns the number of bytes of text
+// accepted. Large pastes are split into chunks that wait for room in the
+// input queue rather than being dropped, so the count is short only when the
+// view closes mid-paste. The text is wrapped in bracketed paste markers when
+// the remote side requested them.
+func (v *TerminalView) Paste(text []byte) int {
+	v.mu.Lock()
+	bracketed := v.emulator != nil && v.emulator.BracketedPaste()
+	v.mu.Unlock()
+
+	if bracketed && !v.queueInput([]byte(pasteStartSeq)) {
+		return 0
+	}
+
+	accepted := 0
+	for accepted < len(text) {
+		n := min(pasteChunkSize, len(text)-accepted)
+		// Keep multi-byte characters within a single chunk
+		for n < len(text)-accepted && n > 0 && !utf8.RuneStart(text[accepted+n]) {
+			n--
+		}
+		if n == 0 {
+			n = min(pasteChunkSize, len(text)-accepted)
+		}
+
+		chunk := append([]byte(nil), text[accepted:accepted+n]...)
+		if !v.queueInput(chunk) {
+			return accepted
+		}
+		accepted += n
+	}
+
+	if bracketed {
+		v.queueInput([]byte(pasteEndSeq))
+	}
+	return accepted
+}
+
+// queueInput waits for room in the input queue regardless of the overflow
+// policy, returning false if the view closes first
+func (v *TerminalView) queueInput(data []byte) bool {
+	select {
+	case <-v.quitCh:
+		return false
+	default:
+	}
+	select {
+	case v.inputCh <- data:
+		return true
+	case <-v.quitCh:
+		return false
+	}
+}
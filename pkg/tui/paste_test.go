@@ -0,0 +1,86 @@
+package tui
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/opd-ai/go-gamelaunch-client/pkg/dgclient"
+)
+
+// drainInput collects input until want bytes have arrived
+func drainInput(t *testing.T, tv *TerminalView, want int) <-chan []byte {
+	t.Helper()
+	out := make(chan []byte, 1)
+	go func() {
+		var buf bytes.Buffer
+		for buf.Len() < want {
+			data, err := tv.HandleInput()
+			if err != nil {
+				break
+			}
+			buf.Write(data)
+		}
+		out <- buf.Bytes()
+	}()
+	return out
+}
+
+func TestPasteLargerThanInputQueue(t *testing.T) {
+	tv, _ := newTestTerminalView(t, 80, 24)
+	text := []byte(strings.Repeat("héllo wörld\n", 2*cap(tv.inputCh)*pasteChunkSize/12))
+
+	got := drainInput(t, tv, len(text))
+	if n := tv.Paste(text); n != len(text) {
+		t.Errorf("Expected all %d bytes accepted, got %d", len(text), n)
+	}
+	if data := <-got; !bytes.Equal(data, text) {
+		t.Errorf("Expected pasted text delivered intact, got %d of %d bytes", len(data), len(text))
+	}
+	if tv.DroppedInputs() != 0 {
+		t.Errorf("Expected no dropped input, got %d", tv.DroppedInputs())
+	}
+}
+
+func TestPasteBracketedWhenRequested(t *testing.T) {
+	tv, _ := newTestTerminalView(t, 80, 24)
+	tv.emulator.ProcessData([]byte("\x1b[?2004h"))
+
+	want := pasteStartSeq + "ls\r" + pasteEndSeq
+	got := drainInput(t, tv, len(want))
+	tv.Paste([]byte("ls\r"))
+	if data := <-got; string(data) != want {
+		t.Errorf("Expected %q, got %q", want, data)
+	}
+}
+
+func TestPasteEventsCollectKeys(t *testing.T) {
+	tv, _ := newTestTerminalView(t, 80, 24)
+
+	tv.processEvent(tcell.NewEventPaste(true))
+	tv.processEvent(tcell.NewEventKey(tcell.KeyRune, 'a', tcell.ModNone))
+	tv.processEvent(tcell.NewEventKey(DefaultCommandPrefix, 0, tcell.ModNone))
+	tv.processEvent(tcell.NewEventKey(tcell.KeyEnter, 0, tcell.ModNone))
+	if len(tv.inputCh) != 0 {
+		t.Fatal("Expected pasted keys to be held until the paste ends")
+	}
+	tv.processEvent(tcell.NewEventPaste(false))
+
+	if data, _ := tv.HandleInput(); string(data) != "a\r" {
+		t.Errorf("Expected pasted text as one input, got %q", data)
+	}
+	if tv.commandMode {
+		t.Error("Expected pasted command prefix not to enter command mode")
+	}
+}
+
+func TestPasteStopsWhenViewCloses(t *testing.T) {
+	view, _ := NewTerminalView(dgclient.DefaultViewOptions())
+	tv := view.(*TerminalView)
+	close(tv.quitCh)
+
+	if n := tv.Paste([]byte("text")); n != 0 {
+		t.Errorf("Expected nothing accepted after close, got %d", n)
+	}
+}
@@ -0,0 +1,31 @@
+package tui
+
+import "fmt"
+
+// Cell size in pixels assumed for pixel size reports, since the emulator
+// has no font metrics of its own
+const (
+	reportCellWidth  = 8
+	reportCellHeight = 16
+)
+
+// respond queues data for the response handler, if one is set
+func (te *TerminalEmulator) respond(data []byte) {
+	if handler := te.responseHandler; handler != nil {
+		te.pendingEvents = append(te.pendingEvents, func() { handler(data) })
+	}
+}
+
+// reportWindow answers the XTWINOPS size queries (CSI 14 t, 18 t and 19 t).
+// The operations that move, resize or iconify the window are ignored so the
+// remote side cannot manipulate the local terminal.
+func (te *TerminalEmulator) reportWindow(op int) {
+	switch op {
+	case 14: // Text area size in pixels
+		te.respond(fmt.Appendf(nil, "\x1b[4;%d;%dt", te.height*reportCellHeight, te.width*reportCellWidth))
+	case 18: // Text area size in characters
+		te.respond(fmt.Appendf(nil, "\x1b[8;%d;%dt", te.height, te.width))
+	case 19: // Screen size in characters
+		te.respond(fmt.Appendf(nil, "\x1b[9;%d;%dt", te.height, te.width))
+	}
+}
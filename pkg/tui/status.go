@@ -0,0 +1,198 @@
+package tui
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/opd-ai/go-gamelaunch-client/pkg/dgclient"
+)
+
+// ConfigStatusLine is the ViewOptions.Config key enabling the status line
+// (default false). When enabled the bottom row is reserved for connection
+// status and the emulator, and therefore the PTY, is one row shorter.
+const ConfigStatusLine = "status_line"
+
+// statusStyle is used to draw the status line
+var statusStyle = tcell.StyleDefault.Reverse(true)
+
+// emulatorHeight returns the rows available to the emulator for a screen of
+// the given height. Must be called with v.mu held.
+func (v *TerminalView) emulatorHeight(screenHeight int) int {
+	if v.statusEnabled && screenHeight > 1 {
+		return screenHeight - 1
+	}
+	return screenHeight
+}
+
+// resizeEmulator resizes the emulator and records the size it adopted.
+// Sizes the emulator rejects, such as zero rows or more than its maximum,
+// are clamped, so GetSize always reports the emulator's size. Must be
+// called with v.mu held.
+func (v *TerminalView) resizeEmulator(width, height int) {
+	if v.emulator == nil {
+		v.width, v.height = width, height
+		return
+	}
+
+	width, height = v.emulator.clampSize(width, height)
+	if err := v.emulator.Resize(width, height); err != nil {
+		return // Keep the size the emulator still has
+	}
+	v.width, v.height = width, height
+}
+
+// SetStatusLineEnabled shows or hides the status line, resizing the emulator
+// area accordingly. The new size reaches the server on the next resize poll.
+func (v *TerminalView) SetStatusLineEnabled(enabled bool) {
+	v.mu.Lock()
+	if v.statusEnabled == enabled {
+		v.mu.Unlock()
+		return
+	}
+	v.statusEnabled = enabled
+
+	if v.screen != nil {
+		width, height := v.screen.Size()
+		v.resizeEmulator(width, v.emulatorHeight(height))
+	}
+	screen := v.screen
+	v.mu.Unlock()
+
+	if screen == nil {
+		return
+	}
+
+	if !enabled {
+		screen.Clear()
+	}
+	v.invalidate()
+	v.requestDraw()
+}
+
+// ToggleStatusLine flips the status line on or off
+func (v *TerminalView) ToggleStatusLine() {
+	v.mu.Lock()
+	enabled := v.statusEnabled
+	v.mu.Unlock()
+
+	v.SetStatusLineEnabled(!enabled)
+}
+
+// SetStatus replaces the status line text
+func (v *TerminalView) SetStatus(text string) {
+	v.mu.Lock()
+	v.status = text
+	v.statusDirty = true
+	active := v.statusEnabled && v.screen != nil
+	v.mu.Unlock()
+
+	if active {
+		v.requestDraw()
+	}
+}
+
+// HandleConnectionEvent updates the status line from a client connection
+// event. It implements dgclient.ConnectionStatusView, so the client calls it
+// for every connection change.
+// A scheduled reconnection attempt is shown as a countdown that ticks every
+// second until the attempt starts or another event arrives.
+func (v *TerminalView) HandleConnectionEvent(ev dgclient.ConnectionEvent) {
+	v.mu.Lock()
+	v.statusGen++
+	gen := v.statusGen
+	v.connEvent = ev
+	v.mu.Unlock()
+
+	v.SetStatus(formatConnectionStatus(ev, ev.Delay))
+	if ev.State == dgclient.StateReconnecting && ev.Delay > time.Second {
+		go v.reconnectCountdown(ev, gen, time.Now().Add(ev.Delay))
+	}
+}
+
+// HandleLatency refreshes the latency shown with the connection status
+// while connected. It implements dgclient.LatencyView.
+func (v *TerminalView) HandleLatency(latency time.Duration) {
+	v.mu.Lock()
+	if v.connEvent.State != dgclient.StateConnected {
+		v.mu.Unlock()
+		return
+	}
+	v.connEvent.Latency = latency
+	ev := v.connEvent
+	v.mu.Unlock()
+
+	v.SetStatus(formatConnectionStatus(ev, 0))
+}
+
+// reconnectCountdown refreshes the status line with the time left before a
+// reconnection attempt while ev is still the latest event
+func (v *TerminalView) reconnectCountdown(ev dgclient.ConnectionEvent, gen uint64, deadline time.Time) {
+	ticker := time.NewTicker(time.Second)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-v.quitCh:
+			return
+		case <-ticker.C:
+		}
+
+		remaining := time.Until(deadline).Round(time.Second)
+		v.mu.Lock()
+		current := v.statusGen == gen
+		v.mu.Unlock()
+		if !current || remaining <= 0 {
+			return
+		}
+		v.SetStatus(formatConnectionStatus(ev, remaining))
+	}
+}
+
+// formatConnectionStatus renders a connection event as status line text,
+// with retryIn the time left before a scheduled reconnection attempt
+func formatConnectionStatus(ev dgclient.ConnectionEvent, retryIn time.Duration) string {
+	var text string
+	switch ev.State {
+	case dgclient.StateConnected:
+		text = fmt.Sprintf("connected to %s:%d", ev.Host, ev.Port)
+	case dgclient.StateReconnecting:
+		text = fmt.Sprintf("reconnecting to %s:%d (attempt %d/%d)", ev.Host, ev.Port, ev.Attempt, ev.MaxAttempts)
+		if retryIn > 0 {
+			text = fmt.Sprintf("reconnecting to %s:%d in %v (attempt %d/%d)",
+				ev.Host, ev.Port, retryIn.Round(time.Second), ev.Attempt, ev.MaxAttempts)
+		}
+	default:
+		text = "disconnected"
+		if ev.Err != nil {
+			text = fmt.Sprintf("disconnected: %v", ev.Err)
+		}
+	}
+
+	if ev.Latency > 0 {
+		text = fmt.Sprintf("%s | latency %v", text, ev.Latency.Round(time.Millisecond))
+	}
+	return text
+}
+
+// drawStatusLine draws the status line on row y when it changed or after a
+// full redraw. Must be called with drawMu held.
+func (v *TerminalView) drawStatusLine(screen tcell.Screen, y int, full bool) {
+	v.mu.Lock()
+	if !v.statusEnabled || (!full && !v.statusDirty) {
+		v.mu.Unlock()
+		return
+	}
+	text := []rune(" " + v.status)
+	width := v.width
+	v.statusDirty = false
+	v.mu.Unlock()
+
+	for x := 0; x < width; x++ {
+		ch := ' '
+		if x < len(text) {
+			ch = text[x]
+		}
+		screen.SetContent(x, y, ch, nil, statusStyle)
+	}
+}
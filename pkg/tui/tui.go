@@ -1,15 +1,39 @@
 package tui
 
 import (
+	"errors"
 	"fmt"
 	"io"
+	"os"
+	"strconv"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/gdamore/tcell/v2"
 	"github.com/opd-ai/go-gamelaunch-client/pkg/dgclient"
+	"golang.org/x/term"
 )
 
+// ErrNotTerminal is returned by Init when stdin or stdout is not a terminal
+var ErrNotTerminal = errors.New("not a terminal")
+
+// ConfigWindowTitle is the ViewOptions.Config key controlling whether the
+// remote window title is propagated to the local terminal (default true)
+const ConfigWindowTitle = "window_title"
+
+// DefaultCommandPrefix is the key that enters client command mode (Ctrl+])
+const DefaultCommandPrefix = tcell.KeyCtrlRightSq
+
+// Title stack sequences (XTWINOPS) used to restore the original title on Close
+const (
+	pushTitleSeq = "\x1b[22;0t"
+	popTitleSeq  = "\x1b[23;0t"
+)
+
+// isTerminal reports whether fd refers to a terminal; replaceable in tests
+var isTerminal = term.IsTerminal
+
 // TerminalView implements dgclient.View using tcell for terminal rendering
 type TerminalView struct {
 	screen   tcell.Screen
@@ -19,41 +43,140 @@ type TerminalView struct {
 	width  int
 	height int
 
-	inputCh chan []byte
-	quitCh  chan struct{}
+	inputCh       chan []byte
+	responseCh    chan []byte
+	quitCh        chan struct{}
+	eventsDone    chan struct{}
+	droppedInputs atomic.Uint64
+
+	// Render throttling: lastGrid is the last drawn screen and spareGrid a
+	// buffer reused for the next snapshot. Both grids and the glyphMap
+	// character substitutions are guarded by drawMu.
+	drawMu    sync.Mutex
+	lastGrid  [][]Cell
+	spareGrid [][]Cell
+	dirty     bool
+	lastDraw  time.Time
+	drawTimer *time.Timer
+	cursor    CursorStyle
+	glyphMap  map[rune]rune
+
+	// Semantic state extraction
+	extractor StateExtractor
+	extracted map[string]any
+
+	// Paste collection between tcell paste start and end events; only
+	// touched by the event goroutine
+	pasting  bool
+	pasteBuf []byte
+
+	// Client command mode entered via the command prefix key
+	commandPrefix   tcell.Key
+	commandMode     bool
+	commandHandlers map[rune]func()
+
+	// Ctrl+C hook; it returns true to keep the key from the server
+	interruptHandler func() bool
+
+	// Window title propagation
+	titleEnabled bool
+	titlePushed  bool
+
+	// Status line reserved below the emulator area
+	statusEnabled bool
+	status        string
+	statusDirty   bool
+	statusGen     uint64
+	connEvent     dgclient.ConnectionEvent
 
 	// Options
 	opts dgclient.ViewOptions
 }
 
+func init() {
+	dgclient.RegisterView("terminal", dgclient.ViewFactoryFunc(NewTerminalView))
+}
+
 // NewTerminalView creates a new terminal-based view
 func NewTerminalView(opts dgclient.ViewOptions) (dgclient.View, error) {
+	titleEnabled := true
+	if enabled, ok := opts.Config[ConfigWindowTitle].(bool); ok {
+		titleEnabled = enabled
+	}
+	statusEnabled, _ := opts.Config[ConfigStatusLine].(bool)
+	glyphMap, _ := opts.Config[ConfigGlyphMap].(map[rune]rune)
+
 	return &TerminalView{
-		opts:    opts,
-		inputCh: make(chan []byte, 100),
-		quitCh:  make(chan struct{}),
+		opts:            opts,
+		commandPrefix:   DefaultCommandPrefix,
+		commandHandlers: make(map[rune]func()),
+		titleEnabled:    titleEnabled,
+		statusEnabled:   statusEnabled,
+		glyphMap:        glyphMap,
+		inputCh:         make(chan []byte, 100),
+		responseCh:      make(chan []byte, 16),
+		quitCh:          make(chan struct{}),
+		eventsDone:      make(chan struct{}),
 	}, nil
 }
 
+// frameInterval bounds how often the screen is redrawn (~60fps)
+const frameInterval = 16 * time.Millisecond
+
 // Init initializes the terminal view
 func (v *TerminalView) Init() error {
+	if err := checkTerminal(); err != nil {
+		return err
+	}
+
 	screen, err := tcell.NewScreen()
 	if err != nil {
 		return fmt.Errorf("failed to create screen: %w", err)
 	}
 
+	// Save the current title so Close can restore it
+	if v.titleEnabled {
+		fmt.Fprint(os.Stdout, pushTitleSeq)
+		v.titlePushed = true
+	}
+
+	return v.initScreen(screen)
+}
+
+// checkTerminal verifies that stdin and stdout are attached to a terminal,
+// since tcell otherwise fails with an opaque error
+func checkTerminal() error {
+	for _, f := range []*os.File{os.Stdin, os.Stdout} {
+		if !isTerminal(int(f.Fd())) {
+			return fmt.Errorf("%w: %s is redirected; the terminal view needs an interactive terminal, "+
+				"run from a terminal or select another view with --view (available: %v)",
+				ErrNotTerminal, f.Name(), dgclient.RegisteredViews())
+		}
+	}
+	return nil
+}
+
+// initScreen completes initialization on a created screen
+func (v *TerminalView) initScreen(screen tcell.Screen) error {
 	if err := screen.Init(); err != nil {
 		return fmt.Errorf("failed to initialize screen: %w", err)
 	}
 
 	v.screen = screen
-	v.width, v.height = screen.Size()
+	screen.EnablePaste()
+	width, height := screen.Size()
+	v.width, v.height = width, v.emulatorHeight(height)
 
 	// Create terminal emulator
 	v.emulator = NewTerminalEmulator(v.width, v.height)
+	v.emulator.SetClipboardHandler(v.setClipboard)
+	v.emulator.SetResponseHandler(v.sendResponse)
+	if v.titleEnabled {
+		v.emulator.SetTitleHandler(v.setTitle)
+	}
 
 	// Set up event handling
-	go v.handleEvents()
+	go v.handleEvents(screen)
 
 	// Clear screen
 	v.screen.Clear()
@@ -62,36 +185,151 @@ func (v *TerminalView) Init() error {
 	return nil
 }
 
-// Render displays the provided data
+// Render displays the provided data. Redraws are coalesced to at most one
+// per frameInterval; data arriving within a frame is drawn by a deferred flush.
 func (v *TerminalView) Render(data []byte) error {
 	// Process data without holding locks
 	v.emulator.ProcessData(data)
-	screenData := v.emulator.GetScreen()
-	cursorX, cursorY := v.emulator.GetCursor()
 
-	// Atomic state check and screen validation
 	v.mu.Lock()
-	screen := v.screen
-	if screen == nil {
+	if v.screen == nil {
 		v.mu.Unlock()
 		return fmt.Errorf("screen not initialized")
 	}
 	v.mu.Unlock()
 
-	// Perform all screen operations without holding mutex
-	screen.Clear()
+	v.requestDraw()
+	return nil
+}
+
+// requestDraw marks the view dirty and draws now, or schedules a flush if
+// the last draw happened less than frameInterval ago
+func (v *TerminalView) requestDraw() {
+	v.mu.Lock()
+	v.dirty = true
+
+	if wait := frameInterval - time.Since(v.lastDraw); wait > 0 {
+		if v.drawTimer == nil {
+			v.drawTimer = time.AfterFunc(wait, v.flush)
+		}
+		v.mu.Unlock()
+		return
+	}
+	v.mu.Unlock()
+
+	v.flush()
+}
+
+// flush draws the emulator screen if it changed since the last draw
+func (v *TerminalView) flush() {
+	v.drawMu.Lock()
+	defer v.drawMu.Unlock()
+
+	v.mu.Lock()
+	v.drawTimer = nil
+	screen := v.screen
+	if screen == nil || !v.dirty {
+		v.mu.Unlock()
+		return
+	}
+	v.dirty = false
+	v.lastDraw = time.Now()
+	v.mu.Unlock()
+
+	screenData := v.emulator.CopyScreen(v.spareGrid)
+	cursorX, cursorY := v.emulator.GetCursor()
+
+	full := v.drawScreen(screen, screenData)
+	v.drawStatusLine(screen, len(screenData), full)
+	if style := v.emulator.GetCursorStyle(); style != v.cursor {
+		// tcell cursor styles share the DECSCUSR numbering
+		screen.SetCursorStyle(tcell.CursorStyle(style))
+		v.cursor = style
+	}
+	if v.emulator.CursorVisible() {
+		screen.ShowCursor(cursorX, cursorY)
+	} else {
+		screen.HideCursor()
+	}
+	screen.Show()
+
+	v.extractState(screenData)
+}
+
+// drawScreen updates only the cells that differ from the last drawn grid,
+// falling back to a full redraw when there is no previous grid or its
+// dimensions differ. It reports whether a full redraw happened.
+// Must be called with drawMu held.
+func (v *TerminalView) drawScreen(screen tcell.Screen, screenData [][]Cell) bool {
+	prev := v.lastGrid
+	full := len(prev) != len(screenData)
+	if !full && len(prev) > 0 && len(prev[0]) != len(screenData[0]) {
+		full = true
+	}
+
+	if full {
+		screen.Clear()
+	}
 
 	for y, row := range screenData {
 		for x, cell := range row {
+			if !full && prev[y][x] == cell {
+				continue
+			}
 			style := v.cellToTcellStyle(cell.Attr)
-			screen.SetContent(x, y, cell.Char, nil, style)
+			if cell.Link != 0 {
+				// Forwarded as OSC 8 by terminals that support hyperlinks
+				if url := v.emulator.LinkURL(cell.Link); url != "" {
+					style = style.Url(url).UrlId(strconv.FormatUint(uint64(cell.Link), 10))
+				}
+			}
+			screen.SetContent(x, y, v.glyph(cell.Char), nil, style)
 		}
 	}
 
-	screen.ShowCursor(cursorX, cursorY)
-	screen.Show()
+	v.spareGrid = v.lastGrid
+	v.lastGrid = screenData
+	return full
+}
 
-	return nil
+// invalidate forces the next draw to repaint every cell
+func (v *TerminalView) invalidate() {
+	v.drawMu.Lock()
+	v.lastGrid = nil
+	v.drawMu.Unlock()
+}
+
+// SetStateExtractor registers an extractor run after every render
+func (v *TerminalView) SetStateExtractor(extractor StateExtractor) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	v.extractor = extractor
+	v.extracted = nil
+}
+
+// ExtractedState returns the fields produced by the state extractor for the
+// most recent render, or nil if no extractor is set
+func (v *TerminalView) ExtractedState() map[string]any {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	return v.extracted
+}
+
+// extractState runs the configured extractor over a screen snapshot
+func (v *TerminalView) extractState(screenData [][]Cell) {
+	v.mu.Lock()
+	extractor := v.extractor
+	v.mu.Unlock()
+
+	if extractor == nil {
+		return
+	}
+
+	fields := extractor.Extract(screenData)
+
+	v.mu.Lock()
+	v.extracted = fields
+	v.mu.Unlock()
 }
 
 // cellToTcellStyle converts cell attributes to tcell style
@@ -99,8 +337,8 @@ func (v *TerminalView) cellToTcellStyle(attr CellAttributes) tcell.Style {
 	style := tcell.StyleDefault
 
 	// Convert colors
-	fg := tcell.NewRGBColor(int32(attr.Foreground.R), int32(attr.Foreground.G), int32(attr.Foreground.B))
-	bg := tcell.NewRGBColor(int32(attr.Background.R), int32(attr.Background.G), int32(attr.Background.B))
+	fg := tcellColor(attr.Foreground)
+	bg := tcellColor(attr.Background)
 
 	style = style.Foreground(fg).Background(bg)
 
@@ -118,12 +356,33 @@ func (v *TerminalView) cellToTcellStyle(attr CellAttributes) tcell.Style {
 	return style
 }
 
+// setClipboard forwards OSC 52 clipboard data to the local terminal
+func (v *TerminalView) setClipboard(selection string, data []byte) {
+	v.mu.Lock()
+	screen := v.screen
+	v.mu.Unlock()
+
+	if screen != nil {
+		screen.SetClipboard(data)
+	}
+}
+
+// setTitle forwards the remote window title to the local terminal
+func (v *TerminalView) setTitle(title string) {
+	v.mu.Lock()
+	screen := v.screen
+	v.mu.Unlock()
+
+	if screen != nil {
+		screen.SetTitle(title)
+	}
+}
+
 // Clear clears the display
 func (v *TerminalView) Clear() error {
 	v.mu.Lock()
-	defer v.mu.Unlock()
-
 	if v.screen == nil {
+		v.mu.Unlock()
 		return fmt.Errorf("screen not initialized")
 	}
 
@@ -132,6 +391,9 @@ func (v *TerminalView) Clear() error {
 		v.emulator.eraseScreen()
 	}
 	v.screen.Show()
+	v.mu.Unlock()
+
+	v.invalidate()
 	return nil
 }
 
@@ -140,13 +402,19 @@ func (v *TerminalView) SetSize(width, height int) error {
 	v.mu.Lock()
 	defer v.mu.Unlock()
 
-	v.width = width
-	v.height = height
+	if err := ValidateSize(width, height, DefaultMaxWidth, DefaultMaxHeight); err != nil {
+		return err
+	}
 
 	if v.emulator != nil {
-		v.emulator.Resize(width, height)
+		if err := v.emulator.Resize(width, height); err != nil {
+			return err
+		}
 	}
 
+	v.width = width
+	v.height = height
+
 	return nil
 }
 
@@ -174,45 +442,43 @@ func (v *TerminalView) Close() error {
 	v.mu.Lock()
 	defer v.mu.Unlock()
 
+	if v.drawTimer != nil {
+		v.drawTimer.Stop()
+		v.drawTimer = nil
+	}
+
 	if v.screen != nil {
 		v.screen.Fini()
 		v.screen = nil
 	}
 
+	if v.titlePushed {
+		fmt.Fprint(os.Stdout, popTitleSeq)
+		v.titlePushed = false
+	}
+
 	return nil
 }
 
-// handleEvents processes terminal events with non-blocking polling
-func (v *TerminalView) handleEvents() {
-	ticker := time.NewTicker(16 * time.Millisecond) // ~60fps for responsive UI
-	defer ticker.Stop()
+// handleEvents blocks in PollEvent and dispatches events until the screen
+// is finalized. Close calls Fini, which makes PollEvent return nil, so the
+// loop exits promptly without periodic wakeups.
+func (v *TerminalView) handleEvents(screen tcell.Screen) {
+	defer close(v.eventsDone)
 
 	for {
+		event := screen.PollEvent()
+		if event == nil {
+			return
+		}
+
 		select {
 		case <-v.quitCh:
 			return
-		case <-ticker.C:
-			// Non-blocking event processing with proper synchronization
-			for {
-				// Check if screen is available without holding mutex during PollEvent
-				v.mu.Lock()
-				screen := v.screen
-				if screen == nil {
-					v.mu.Unlock()
-					return // Screen has been closed, exit gracefully
-				}
-				v.mu.Unlock()
-
-				// Call PollEvent without holding the mutex to prevent deadlock
-				event := screen.PollEvent()
-
-				if event == nil {
-					break // No more events available
-				}
-
-				v.processEvent(event) // Extracted for testability
-			}
+		default:
 		}
+
+		v.processEvent(event)
 	}
 }
 
@@ -221,33 +487,137 @@ func (v *TerminalView) processEvent(event tcell.Event) {
 	switch ev := event.(type) {
 	case *tcell.EventKey:
 		v.handleKeyEvent(ev) // Now actually called
+	case *tcell.EventPaste:
+		if ev.Start() {
+			v.pasting = true
+			v.pasteBuf = v.pasteBuf[:0]
+		} else if v.pasting {
+			v.pasting = false
+			v.Paste(v.pasteBuf)
+		}
 	case *tcell.EventResize:
 		// Capture new dimensions
 		newWidth, newHeight := ev.Size()
 
 		// Atomic update of internal state
 		v.mu.Lock()
-		v.width, v.height = newWidth, newHeight
-		if v.emulator != nil {
-			v.emulator.Resize(newWidth, newHeight)
+		screen := v.screen
+		if screen == nil {
+			v.mu.Unlock()
+			return // Closed while the event was in flight
+		}
+		v.resizeEmulator(newWidth, v.emulatorHeight(newHeight))
+		v.mu.Unlock()
+
+		// Screen sync without holding mutex, then repaint in full
+		screen.Sync()
+		v.invalidate()
+		v.mu.Lock()
+		v.dirty = true
+		v.mu.Unlock()
+		v.flush()
+	}
+}
+
+// SetCommandPrefix changes the key that enters client command mode.
+// Pressing the prefix twice sends it to the server literally.
+func (v *TerminalView) SetCommandPrefix(key tcell.Key) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	v.commandPrefix = key
+	v.commandMode = false
+}
+
+// SetInterruptHandler registers fn to run when Ctrl+C is typed. The tty is
+// in raw mode, so Ctrl+C never raises SIGINT; it is sent to the server as
+// ETX unless fn returns true. A nil fn restores the default.
+func (v *TerminalView) SetInterruptHandler(fn func() bool) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	v.interruptHandler = fn
+}
+
+// SetCommandHandler registers the action run when key is pressed in command
+// mode, e.g. 'q' to quit or 'r' to reconnect. A nil fn removes the handler.
+func (v *TerminalView) SetCommandHandler(key rune, fn func()) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	if fn == nil {
+		delete(v.commandHandlers, key)
+		return
+	}
+	v.commandHandlers[key] = fn
+}
+
+// handleCommandKey runs the command mode state machine. It reports whether
+// the event was consumed, and returns data to send when the prefix is
+// pressed twice.
+func (v *TerminalView) handleCommandKey(ev *tcell.EventKey) (consumed bool, literal []byte) {
+	v.mu.Lock()
+	prefix := v.commandPrefix
+
+	if !v.commandMode {
+		if ev.Key() == prefix {
+			v.commandMode = true
+			v.mu.Unlock()
+			return true, nil
 		}
 		v.mu.Unlock()
+		return false, nil
+	}
+
+	// In command mode: the next key selects an action
+	v.commandMode = false
+	if ev.Key() == prefix {
+		v.mu.Unlock()
+		return true, []byte{byte(prefix)}
+	}
+
+	var handler func()
+	if ev.Key() == tcell.KeyRune {
+		handler = v.commandHandlers[ev.Rune()]
+	}
+	v.mu.Unlock()
 
-		// Screen sync without holding mutex
-		v.screen.Sync()
+	// Run the action without holding the lock; unknown keys are discarded
+	if handler != nil {
+		handler()
 	}
+	return true, nil
 }
 
 // handleKeyEvent processes keyboard input
 func (v *TerminalView) handleKeyEvent(ev *tcell.EventKey) {
 	var data []byte
 
+	// Pasted text is never a client command
+	if !v.pasting {
+		if consumed, literal := v.handleCommandKey(ev); consumed {
+			if literal != nil {
+				v.sendInput(literal)
+			}
+			return
+		}
+
+		if ev.Key() == tcell.KeyCtrlC {
+			v.mu.Lock()
+			interrupt := v.interruptHandler
+			v.mu.Unlock()
+			if interrupt != nil && interrupt() {
+				return
+			}
+		}
+	}
+
 	// Handle special keys
 	switch ev.Key() {
 	case tcell.KeyRune:
 		data = []byte(string(ev.Rune()))
 	case tcell.KeyEnter:
 		data = []byte("\r")
+		if v.emulator != nil && v.emulator.NewlineMode() {
+			data = []byte("\r\n")
+		}
 	case tcell.KeyBackspace, tcell.KeyBackspace2:
 		data = []byte{8} // ASCII backspace
 	case tcell.KeyTab:
@@ -273,9 +643,71 @@ func (v *TerminalView) handleKeyEvent(ev *tcell.EventKey) {
 		return
 	}
 
+	// Application cursor keys (DECCKM) send arrows as SS3 sequences
+	if len(data) == 3 && data[1] == '[' && data[2] >= 'A' && data[2] <= 'D' && v.emulator != nil && v.emulator.ApplicationCursorKeys() {
+		data[1] = 'O'
+	}
+
+	if v.pasting {
+		v.pasteBuf = append(v.pasteBuf, data...)
+		return
+	}
+	v.sendInput(data)
+}
+
+// Responses returns the emulator's replies to terminal queries. It
+// implements dgclient.ResponseView, so replies reach the session without
+// passing through the input path.
+func (v *TerminalView) Responses() <-chan []byte {
+	return v.responseCh
+}
+
+// sendResponse queues a reply to a terminal query. Replies are small and
+// rare; if the client has stopped reading them the reply is dropped rather
+// than stalling the emulator.
+func (v *TerminalView) sendResponse(data []byte) {
+	select {
+	case v.responseCh <- data:
+	default:
+	}
+}
+
+// sendInput queues input for HandleInput, applying the configured
+// overflow policy when the queue is full
+func (v *TerminalView) sendInput(data []byte) {
 	select {
 	case v.inputCh <- data:
+		return
 	default:
-		// Drop input if buffer is full
 	}
+
+	switch v.opts.InputOverflow {
+	case dgclient.InputOverflowBlock:
+		select {
+		case v.inputCh <- data:
+		case <-v.quitCh:
+			v.droppedInputs.Add(1)
+		}
+	case dgclient.InputOverflowDropOldest:
+		for {
+			select {
+			case v.inputCh <- data:
+				return
+			default:
+			}
+			select {
+			case <-v.inputCh:
+				v.droppedInputs.Add(1)
+			default:
+			}
+		}
+	default:
+		v.droppedInputs.Add(1)
+	}
+}
+
+// DroppedInputs returns the number of input events discarded because the
+// input queue was full
+func (v *TerminalView) DroppedInputs() uint64 {
+	return v.droppedInputs.Load()
 }
@@ -0,0 +1,552 @@
+package tui
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/opd-ai/go-gamelaunch-client/pkg/dgclient"
+)
+
+// countingScreen wraps a tcell.Screen and counts SetContent calls
+type countingScreen struct {
+	tcell.Screen
+	setContentCalls atomic.Int64
+	title           atomic.Value
+}
+
+func (s *countingScreen) SetTitle(title string) {
+	s.title.Store(title)
+	s.Screen.SetTitle(title)
+}
+
+func (s *countingScreen) SetContent(x, y int, primary rune, combining []rune, style tcell.Style) {
+	s.setContentCalls.Add(1)
+	s.Screen.SetContent(x, y, primary, combining, style)
+}
+
+// newTestTerminalView creates a TerminalView backed by a simulation screen
+func newTestTerminalView(tb testing.TB, width, height int) (*TerminalView, *countingScreen) {
+	tb.Helper()
+
+	sim := tcell.NewSimulationScreen("")
+	screen := &countingScreen{Screen: sim}
+
+	view, _ := NewTerminalView(dgclient.DefaultViewOptions())
+	tv := view.(*TerminalView)
+	if err := tv.initScreen(screen); err != nil {
+		tb.Fatalf("initScreen() failed: %v", err)
+	}
+	sim.SetSize(width, height)
+	tv.SetSize(width, height)
+	tb.Cleanup(func() { tv.Close() })
+
+	return tv, screen
+}
+
+// renderNow processes data and draws immediately, bypassing frame throttling
+func (v *TerminalView) renderNow(data []byte) {
+	v.emulator.ProcessData(data)
+	v.mu.Lock()
+	v.dirty = true
+	v.mu.Unlock()
+	v.flush()
+}
+
+func TestRenderIncrementalUpdatesOnlyChangedCells(t *testing.T) {
+	tv, screen := newTestTerminalView(t, 80, 24)
+
+	tv.renderNow([]byte("Hello"))
+	full := screen.setContentCalls.Load()
+	if full != 80*24 {
+		t.Fatalf("Expected full redraw of %d cells, got %d", 80*24, full)
+	}
+
+	tv.renderNow([]byte("!"))
+	if got := screen.setContentCalls.Load() - full; got != 1 {
+		t.Errorf("Expected 1 SetContent call for incremental update, got %d", got)
+	}
+}
+
+func TestRenderCoalescesFrames(t *testing.T) {
+	tv, screen := newTestTerminalView(t, 80, 24)
+
+	tv.Render([]byte("a"))
+	base := screen.setContentCalls.Load()
+
+	// Rapid renders within one frame are deferred to a single flush
+	for i := 0; i < 10; i++ {
+		tv.Render([]byte("b"))
+	}
+	if got := screen.setContentCalls.Load(); got != base {
+		t.Errorf("Expected renders within a frame to be deferred, got %d extra SetContent calls", got-base)
+	}
+
+	time.Sleep(5 * frameInterval)
+	if got := screen.setContentCalls.Load() - base; got != 10 {
+		t.Errorf("Expected deferred flush to draw 10 changed cells, got %d", got)
+	}
+}
+
+func BenchmarkRenderIncremental(b *testing.B) {
+	tv, screen := newTestTerminalView(b, 80, 24)
+	tv.renderNow([]byte("\x1b[2J"))
+	start := screen.setContentCalls.Load()
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		tv.renderNow([]byte{'\r', byte('a' + i%26)})
+	}
+	b.ReportMetric(float64(screen.setContentCalls.Load()-start)/float64(b.N), "setcontent/op")
+}
+
+func BenchmarkRenderFullRedraw(b *testing.B) {
+	tv, screen := newTestTerminalView(b, 80, 24)
+	tv.renderNow([]byte("\x1b[2J"))
+	start := screen.setContentCalls.Load()
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		tv.invalidate()
+		tv.renderNow([]byte{'\r', byte('a' + i%26)})
+	}
+	b.ReportMetric(float64(screen.setContentCalls.Load()-start)/float64(b.N), "setcontent/op")
+}
+
+func TestInitFailsWithoutTerminal(t *testing.T) {
+	orig := isTerminal
+	isTerminal = func(fd int) bool { return false }
+	defer func() { isTerminal = orig }()
+
+	view, _ := NewTerminalView(dgclient.DefaultViewOptions())
+	err := view.Init()
+	if !errors.Is(err, ErrNotTerminal) {
+		t.Fatalf("Expected ErrNotTerminal, got %v", err)
+	}
+	if !strings.Contains(err.Error(), "--view") {
+		t.Errorf("Expected error to suggest --view, got %q", err.Error())
+	}
+}
+
+func TestRenderPropagatesWindowTitle(t *testing.T) {
+	tv, screen := newTestTerminalView(t, 80, 24)
+
+	tv.renderNow([]byte("\x1b]2;NetHack - Dlvl 3\x07"))
+
+	if got, _ := screen.title.Load().(string); got != "NetHack - Dlvl 3" {
+		t.Errorf("Expected window title 'NetHack - Dlvl 3', got %q", got)
+	}
+}
+
+func TestCommandPrefixModeTransitions(t *testing.T) {
+	view, _ := NewTerminalView(dgclient.DefaultViewOptions())
+	tv := view.(*TerminalView)
+
+	quit := 0
+	tv.SetCommandHandler('q', func() { quit++ })
+
+	prefix := tcell.NewEventKey(DefaultCommandPrefix, 0, tcell.ModCtrl)
+	key := func(r rune) *tcell.EventKey { return tcell.NewEventKey(tcell.KeyRune, r, tcell.ModNone) }
+
+	// Prefix followed by a bound key runs the action and sends nothing
+	tv.handleKeyEvent(prefix)
+	tv.handleKeyEvent(key('q'))
+	if quit != 1 {
+		t.Errorf("Expected quit handler to run once, ran %d times", quit)
+	}
+	if len(tv.inputCh) != 0 {
+		t.Errorf("Expected no input sent for command, got %d events", len(tv.inputCh))
+	}
+
+	// After the command, keys go to the server again
+	tv.handleKeyEvent(key('q'))
+	if got := <-tv.inputCh; string(got) != "q" {
+		t.Errorf("Expected 'q' sent to server after command mode, got %q", got)
+	}
+
+	// Prefix twice sends the prefix literally
+	tv.handleKeyEvent(prefix)
+	tv.handleKeyEvent(prefix)
+	if got := <-tv.inputCh; len(got) != 1 || got[0] != 0x1d {
+		t.Errorf("Expected literal Ctrl+] (0x1d), got %q", got)
+	}
+
+	// Unbound keys in command mode are discarded
+	tv.handleKeyEvent(prefix)
+	tv.handleKeyEvent(key('x'))
+	if len(tv.inputCh) != 0 || quit != 1 {
+		t.Errorf("Expected unbound command key to be discarded")
+	}
+}
+
+// rowText returns the text of row y on a simulation screen
+func rowText(screen tcell.Screen, y, width int) string {
+	var sb strings.Builder
+	for x := 0; x < width; x++ {
+		ch, _, _, _ := screen.GetContent(x, y)
+		sb.WriteRune(ch)
+	}
+	return strings.TrimRight(sb.String(), " ")
+}
+
+func TestStatusLineReservesBottomRow(t *testing.T) {
+	tv, screen := newTestTerminalView(t, 80, 24)
+	tv.SetStatusLineEnabled(true)
+
+	if _, h := tv.GetSize(); h != 23 {
+		t.Errorf("Expected emulator height 23 with status line, got %d", h)
+	}
+
+	tv.HandleConnectionEvent(dgclient.ConnectionEvent{
+		State: dgclient.StateReconnecting, Host: "nethack.example.com", Port: 22, Attempt: 2, MaxAttempts: 3,
+	})
+	time.Sleep(3 * frameInterval)
+
+	want := " reconnecting to nethack.example.com:22 (attempt 2/3)"
+	if got := rowText(screen, 23, 80); got != want {
+		t.Errorf("Expected status line %q, got %q", want, got)
+	}
+
+	tv.SetStatusLineEnabled(false)
+	if _, h := tv.GetSize(); h != 24 {
+		t.Errorf("Expected emulator height 24 without status line, got %d", h)
+	}
+}
+
+func TestResponsesBypassInput(t *testing.T) {
+	tv, _ := newTestTerminalView(t, 80, 24)
+	tv.renderNow([]byte("\x1b[18t"))
+
+	select {
+	case reply := <-tv.Responses():
+		if string(reply) != "\x1b[8;24;80t" {
+			t.Errorf("Expected size reply, got %q", reply)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Expected a reply on the response channel")
+	}
+	if len(tv.inputCh) != 0 {
+		t.Errorf("Expected no reply queued as input, got %d events", len(tv.inputCh))
+	}
+}
+
+func TestHandleLatency(t *testing.T) {
+	tv, screen := newTestTerminalView(t, 80, 24)
+	tv.SetStatusLineEnabled(true)
+
+	tv.HandleLatency(50 * time.Millisecond)
+	time.Sleep(3 * frameInterval)
+	if got := rowText(screen, 23, 80); got != "" {
+		t.Errorf("Expected no status before a connection event, got %q", got)
+	}
+
+	tv.HandleConnectionEvent(dgclient.ConnectionEvent{State: dgclient.StateConnected, Host: "nethack.example.com", Port: 22})
+	tv.HandleLatency(50 * time.Millisecond)
+	time.Sleep(3 * frameInterval)
+
+	want := " connected to nethack.example.com:22 | latency 50ms"
+	if got := rowText(screen, 23, 80); got != want {
+		t.Errorf("Expected status line %q, got %q", want, got)
+	}
+}
+
+func TestSetSizeRejectsOversized(t *testing.T) {
+	tv, _ := newTestTerminalView(t, 80, 24)
+
+	if err := tv.SetSize(100000, 100000); !errors.Is(err, dgclient.ErrInvalidTerminalSize) {
+		t.Errorf("Expected ErrInvalidTerminalSize, got %v", err)
+	}
+	if err := tv.SetSize(0, 0); !errors.Is(err, dgclient.ErrInvalidTerminalSize) {
+		t.Errorf("Expected ErrInvalidTerminalSize for zero size, got %v", err)
+	}
+	if w, h := tv.GetSize(); w != 80 || h != 24 {
+		t.Errorf("Expected size to stay 80x24, got %dx%d", w, h)
+	}
+}
+
+func TestRenderKeepsParserStateAcrossCalls(t *testing.T) {
+	tv, screen := newTestTerminalView(t, 80, 24)
+
+	// A cursor move, title and color change split into single-byte reads
+	for _, b := range []byte("\x1b[5;10H\x1b]2;split\x07\x1b[1;31mX") {
+		if err := tv.Render([]byte{b}); err != nil {
+			t.Fatalf("Render() failed: %v", err)
+		}
+	}
+	tv.flush()
+
+	if got := rowText(screen, 4, 80); got != strings.Repeat(" ", 9)+"X" {
+		t.Errorf("Expected X at column 10 of row 5, got %q", got)
+	}
+	if x, y := tv.emulator.GetCursor(); x != 10 || y != 4 {
+		t.Errorf("Expected cursor at (10,4), got (%d,%d)", x, y)
+	}
+	if tv.emulator.GetTitle() != "split" {
+		t.Errorf("Expected title %q, got %q", "split", tv.emulator.GetTitle())
+	}
+
+	cell := tv.emulator.GetScreen()[4][9]
+	if !cell.Attr.Bold || cell.Attr.Foreground != getANSIColor(1) {
+		t.Errorf("Expected bold red attributes, got %+v", cell.Attr)
+	}
+}
+
+func TestGlyphMapSubstitutesCharacters(t *testing.T) {
+	tv, screen := newTestTerminalView(t, 80, 24)
+	tv.SetGlyphMap(map[rune]rune{'·': '.', '◆': '*'})
+
+	tv.renderNow([]byte("a·b◆c"))
+
+	if got := rowText(screen, 0, 80); got != "a.b*c" {
+		t.Errorf("Expected substituted row %q, got %q", "a.b*c", got)
+	}
+	if got := tv.emulator.GetScreen()[0][1].Char; got != '·' {
+		t.Errorf("Expected emulator to keep original rune, got %q", got)
+	}
+}
+
+func TestParseGlyphMap(t *testing.T) {
+	glyphs, err := ParseGlyphMap(map[string]string{"·": ".", "q": "-"})
+	if err != nil {
+		t.Fatalf("ParseGlyphMap() failed: %v", err)
+	}
+	if glyphs['·'] != '.' || glyphs['q'] != '-' {
+		t.Errorf("Unexpected glyph map: %v", glyphs)
+	}
+
+	if _, err := ParseGlyphMap(map[string]string{"ab": "."}); err == nil {
+		t.Error("Expected error for multi-character key")
+	}
+}
+
+func BenchmarkDrawScreen(b *testing.B) {
+	for _, size := range []struct{ w, h int }{{80, 24}, {200, 60}} {
+		for _, mode := range []string{"sparse", "full"} {
+			b.Run(fmt.Sprintf("%s/%dx%d", mode, size.w, size.h), func(b *testing.B) {
+				tv, _ := newTestTerminalView(b, size.w, size.h)
+				base := tv.emulator.GetScreen()
+
+				// Alternate between two grids differing in one cell or in every cell
+				changed := tv.emulator.GetScreen()
+				changed[0][0].Char = 'x'
+				if mode == "full" {
+					for y := range changed {
+						for x := range changed[y] {
+							changed[y][x].Char = 'x'
+						}
+					}
+				}
+				grids := [2][][]Cell{base, changed}
+
+				tv.drawMu.Lock()
+				defer tv.drawMu.Unlock()
+				tv.drawScreen(tv.screen, base)
+
+				b.ReportAllocs()
+				b.ResetTimer()
+				for i := 0; i < b.N; i++ {
+					tv.drawScreen(tv.screen, grids[(i+1)%2])
+				}
+			})
+		}
+	}
+}
+
+func TestCloseStopsEventLoop(t *testing.T) {
+	view, _ := NewTerminalView(dgclient.DefaultViewOptions())
+	tv := view.(*TerminalView)
+	screen := tcell.NewSimulationScreen("")
+	if err := tv.initScreen(screen); err != nil {
+		t.Fatalf("initScreen() failed: %v", err)
+	}
+
+	// The blocking loop still delivers events
+	screen.InjectKey(tcell.KeyRune, 'x', tcell.ModNone)
+	select {
+	case input := <-tv.inputCh:
+		if string(input) != "x" {
+			t.Errorf("Expected input %q, got %q", "x", input)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Event loop did not deliver key event")
+	}
+
+	tv.Close()
+	select {
+	case <-tv.eventsDone:
+	case <-time.After(time.Second):
+		t.Fatal("Close did not stop the event loop")
+	}
+}
+
+// newOverflowView returns a view with its input queue already saturated
+func newOverflowView(policy dgclient.InputOverflowPolicy) *TerminalView {
+	opts := dgclient.DefaultViewOptions()
+	opts.InputOverflow = policy
+	view, _ := NewTerminalView(opts)
+	tv := view.(*TerminalView)
+	for i := 0; i < cap(tv.inputCh); i++ {
+		tv.sendInput([]byte{byte(i)})
+	}
+	return tv
+}
+
+func TestInputOverflowDrop(t *testing.T) {
+	tv := newOverflowView(dgclient.InputOverflowDrop)
+	tv.sendInput([]byte("new"))
+
+	if got := tv.DroppedInputs(); got != 1 {
+		t.Errorf("Expected 1 dropped input, got %d", got)
+	}
+	if first := <-tv.inputCh; first[0] != 0 {
+		t.Errorf("Expected oldest input to be kept, got %v", first)
+	}
+}
+
+func TestInputOverflowDropOldest(t *testing.T) {
+	tv := newOverflowView(dgclient.InputOverflowDropOldest)
+	tv.sendInput([]byte("new"))
+
+	if got := tv.DroppedInputs(); got != 1 {
+		t.Errorf("Expected 1 dropped input, got %d", got)
+	}
+	if first := <-tv.inputCh; first[0] != 1 {
+		t.Errorf("Expected oldest input to be discarded, got %v", first)
+	}
+
+	var last []byte
+	for len(tv.inputCh) > 0 {
+		last = <-tv.inputCh
+	}
+	if string(last) != "new" {
+		t.Errorf("Expected new input at the end of the queue, got %q", last)
+	}
+}
+
+func TestInputOverflowBlock(t *testing.T) {
+	tv := newOverflowView(dgclient.InputOverflowBlock)
+
+	sent := make(chan struct{})
+	go func() {
+		tv.sendInput([]byte("new"))
+		close(sent)
+	}()
+
+	select {
+	case <-sent:
+		t.Fatal("Expected sendInput to block on a full queue")
+	case <-time.After(20 * time.Millisecond):
+	}
+
+	if _, err := tv.HandleInput(); err != nil {
+		t.Fatalf("HandleInput() failed: %v", err)
+	}
+	select {
+	case <-sent:
+	case <-time.After(time.Second):
+		t.Fatal("Expected sendInput to complete once the queue had room")
+	}
+	if got := tv.DroppedInputs(); got != 0 {
+		t.Errorf("Expected no dropped input, got %d", got)
+	}
+
+	// A blocked send is released when the view closes
+	released := make(chan struct{})
+	go func() {
+		tv.sendInput([]byte("late"))
+		close(released)
+	}()
+	time.Sleep(10 * time.Millisecond)
+	close(tv.quitCh)
+	select {
+	case <-released:
+	case <-time.After(time.Second):
+		t.Fatal("Expected blocked sendInput to return after close")
+	}
+	if got := tv.DroppedInputs(); got != 1 {
+		t.Errorf("Expected 1 dropped input after close, got %d", got)
+	}
+}
+
+func TestFormatReconnectCountdown(t *testing.T) {
+	ev := dgclient.ConnectionEvent{
+		State: dgclient.StateReconnecting, Host: "hdf", Port: 22, Attempt: 2, MaxAttempts: 3, Delay: 5 * time.Second,
+	}
+
+	want := "reconnecting to hdf:22 in 4s (attempt 2/3)"
+	if got := formatConnectionStatus(ev, 4200*time.Millisecond); got != want {
+		t.Errorf("Expected %q, got %q", want, got)
+	}
+}
+
+func TestInterruptHandler(t *testing.T) {
+	tv, _ := newTestTerminalView(t, 80, 24)
+	ctrlC := tcell.NewEventKey(tcell.KeyCtrlC, 0, tcell.ModCtrl)
+
+	consume := true
+	calls := 0
+	tv.SetInterruptHandler(func() bool {
+		calls++
+		return consume
+	})
+
+	tv.handleKeyEvent(ctrlC)
+	if calls != 1 || len(tv.inputCh) != 0 {
+		t.Errorf("Expected Ctrl+C to be consumed by the handler, got %d calls, %d inputs", calls, len(tv.inputCh))
+	}
+
+	consume = false
+	tv.handleKeyEvent(ctrlC)
+	if got := <-tv.inputCh; string(got) != "\x03" {
+		t.Errorf("Expected Ctrl+C passed on as ETX, got %q", got)
+	}
+}
+
+func TestResizeEventKeepsSizeInSync(t *testing.T) {
+	tests := []struct {
+		name          string
+		width, height int
+		wantW, wantH  int
+	}{
+		{"normal", 100, 30, 100, 30},
+		{"no rows", 100, 0, 100, 1},
+		{"beyond maximum", DefaultMaxWidth + 5, 30, DefaultMaxWidth, 30},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tv, _ := newTestTerminalView(t, 80, 24)
+			tv.processEvent(tcell.NewEventResize(tt.width, tt.height))
+
+			w, h := tv.GetSize()
+			if w != tt.wantW || h != tt.wantH {
+				t.Errorf("Expected view size %dx%d, got %dx%d", tt.wantW, tt.wantH, w, h)
+			}
+			if ew, eh := tv.emulator.width, tv.emulator.height; ew != w || eh != h {
+				t.Errorf("Expected emulator size %dx%d to match the view, got %dx%d", w, h, ew, eh)
+			}
+		})
+	}
+}
+
+func TestEnterFollowsNewlineMode(t *testing.T) {
+	tv, _ := newTestTerminalView(t, 80, 24)
+	enter := tcell.NewEventKey(tcell.KeyEnter, 0, tcell.ModNone)
+
+	tv.handleKeyEvent(enter)
+	if got := <-tv.inputCh; string(got) != "\r" {
+		t.Errorf("Expected Enter to send CR, got %q", got)
+	}
+
+	tv.emulator.ProcessData([]byte("\x1b[20h"))
+	tv.handleKeyEvent(enter)
+	if got := <-tv.inputCh; string(got) != "\r\n" {
+		t.Errorf("Expected Enter to send CR LF in newline mode, got %q", got)
+	}
+}